@@ -1,9 +1,57 @@
 package permission
 
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // AccessControl manages entities and resources, allowing permission assignment.
+//
+// Entities are indexed by tenant and ID and resource trees by tenant and the
+// ID of their root, so each ID can be registered only once in a tenant. Other
+// resources are resolved through the tree, so they are found at their current
+// path after being moved.
+//
+// Methods of AccessControl are safe for concurrent use, so permission checks may
+// run while other goroutines grant permissions or change hierarchies. The
+// Entities and Resources slices should only be read when no registration is
+// in progress.
 type AccessControl struct {
 	Entities  []*Entity
 	Resources []*Resource
+
+	entities  map[registryKey]*Entity
+	resources map[registryKey]*Resource
+	// registered holds the registered resources, their roots are kept in
+	// resources as the hierarchy changes, see Resource.registrations.
+	registered map[*Resource]bool
+	roles      map[string]*Role
+	strategy   Strategy
+	clock      func() time.Time
+	mu         sync.RWMutex
+
+	// implications maps permissions to the permissions they imply, sets marks
+	// the permissions declared as named sets.
+	implications map[Permission][]Permission
+	sets         map[Permission]bool
+
+	// declared is the registry of permissions, enforced in the strict mode.
+	declared map[Permission]PermissionInfo
+	strict   bool
+	types    map[string]ResourceType
+
+	// tuples indexes relation tuples by object and relation, relations holds
+	// rewrites of relations by object type.
+	tuples    map[ObjectRef]map[string][]SubjectRef
+	relations map[string]map[string][]Rewrite
+
+	// cache memoizes decisions when enabled, generation counts changes of
+	// settings like implications or the strategy.
+	cache      *decisionCache
+	generation atomic.Uint64
 }
 
 // NewAccessControl initializes a new AccessControl instance.
@@ -12,14 +60,25 @@ type AccessControl struct {
 //
 //	ac := permission.NewAccessControl()
 //	fmt.Println(len(ac.Entities)) // Output: 0
-func NewAccessControl() *AccessControl {
-	return &AccessControl{
-		Entities:  []*Entity{},
-		Resources: []*Resource{},
+func NewAccessControl(options ...Option) *AccessControl {
+	ac := &AccessControl{
+		Entities:   []*Entity{},
+		Resources:  []*Resource{},
+		entities:   make(map[registryKey]*Entity),
+		resources:  make(map[registryKey]*Resource),
+		registered: make(map[*Resource]bool),
+		roles:      make(map[string]*Role),
+	}
+
+	for _, option := range options {
+		option(ac)
 	}
+
+	return ac
 }
 
 // CreateResource creates a new resource and adds it to the system.
+// It panics when a resource with the same path is already registered.
 //
 // Example:
 //
@@ -34,6 +93,8 @@ func (ac *AccessControl) CreateResource(id string) *Resource {
 }
 
 // AddResource manually adds a resource to the access control system.
+// It panics when a different resource with the same path is already registered,
+// use RegisterResource to get an error instead.
 //
 // Example:
 //
@@ -41,11 +102,128 @@ func (ac *AccessControl) CreateResource(id string) *Resource {
 //	doc := permission.NewResource("document")
 //	ac.AddResource(doc)
 func (ac *AccessControl) AddResource(resource *Resource) *AccessControl {
-	ac.Resources = append(ac.Resources, resource)
+	if err := ac.RegisterResource(resource); err != nil {
+		panic(err)
+	}
 	return ac
 }
 
+// RegisterResource adds a resource to the access control system, together
+// with the tree it belongs to. Registering the same resource twice is a no-op.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	if err := ac.RegisterResource(permission.NewResource("document")); err != nil {
+//		// errors.Is(err, permission.ErrDuplicateResource)
+//	}
+func (ac *AccessControl) RegisterResource(resource *Resource) error {
+	if resource == nil {
+		return nil
+	}
+
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.registered[resource] {
+		return nil
+	}
+	root := rootResource(resource)
+	key := registryKey{tenant: root.Tenant, id: root.ID}
+	if existing := ac.registeredRoot(key); existing != nil && existing != root {
+		return fmt.Errorf("%w: %s", ErrDuplicateResource, key)
+	}
+
+	if ac.resources == nil {
+		ac.resources = make(map[registryKey]*Resource)
+	}
+	if ac.registered == nil {
+		ac.registered = make(map[*Resource]bool)
+	}
+	ac.resources[key] = root
+	ac.registered[resource] = true
+	ac.Resources = append(ac.Resources, resource)
+	if root.registrations == nil {
+		root.registrations = make(map[*AccessControl]int)
+	}
+	root.registrations[ac]++
+	return nil
+}
+
+// registeredRoot returns the registered root resource of the key, the caller
+// must hold ac.mu. A root renamed since it was stored does not match.
+func (ac *AccessControl) registeredRoot(key registryKey) *Resource {
+	if root := ac.resources[key]; root != nil && key.matchesRoot(root) {
+		return root
+	}
+	return nil
+}
+
+// addRoot stores the root of a tree holding registered resources, unless
+// another tree with the same key is stored. The caller must hold ac.mu.
+func (ac *AccessControl) addRoot(root *Resource) {
+	key := registryKey{tenant: root.Tenant, id: root.ID}
+	if ac.registeredRoot(key) == nil {
+		ac.resources[key] = root
+	}
+}
+
+// dropRoot removes the root of a tree holding no registered resources any
+// more. The caller must hold ac.mu.
+func (ac *AccessControl) dropRoot(root *Resource) {
+	key := registryKey{tenant: root.Tenant, id: root.ID}
+	if ac.resources[key] == root {
+		delete(ac.resources, key)
+	}
+}
+
+// RegisterResources adds multiple resources, stopping at the first error.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	err := ac.RegisterResources(permission.NewResource("res1"), permission.NewResource("res2"))
+func (ac *AccessControl) RegisterResources(resources ...*Resource) error {
+	for _, resource := range resources {
+		if err := ac.RegisterResource(resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetResource finds a resource by its path, e.g. "web/comments/comment1".
+// Sub-resources of registered resources are resolved through SubResources,
+// see ResolveResource.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.CreateResource("web").CreateSub("comments")
+//	res, err := ac.GetResource("web/comments")
+func (ac *AccessControl) GetResource(path string) (*Resource, error) {
+	return ac.ResolveResource(path)
+}
+
+// MustGetResource is like GetResource but panics when the resource does not exist.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.CreateResource("document")
+//	doc := ac.MustGetResource("document")
+func (ac *AccessControl) MustGetResource(path string) *Resource {
+	resource, err := ac.GetResource(path)
+	if err != nil {
+		panic(err)
+	}
+	return resource
+}
+
 // CreateEntity creates a new entity and adds it to the system.
+// It panics when an entity with the same ID is already registered.
 //
 // Example:
 //
@@ -59,6 +237,8 @@ func (ac *AccessControl) CreateEntity(id string) *Entity {
 }
 
 // AddEntity manually adds an entity to the access control system.
+// It panics when a different entity with the same ID is already registered,
+// use RegisterEntity to get an error instead.
 //
 // Example:
 //
@@ -66,10 +246,87 @@ func (ac *AccessControl) CreateEntity(id string) *Entity {
 //	user := permission.NewEntity("user1")
 //	ac.AddEntity(user)
 func (ac *AccessControl) AddEntity(entity *Entity) *AccessControl {
-	ac.Entities = append(ac.Entities, entity)
+	if err := ac.RegisterEntity(entity); err != nil {
+		panic(err)
+	}
 	return ac
 }
 
+// RegisterEntity adds an entity to the access control system.
+// Registering the same entity twice is a no-op.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	if err := ac.RegisterEntity(permission.NewEntity("user1")); err != nil {
+//		// errors.Is(err, permission.ErrDuplicateEntity)
+//	}
+func (ac *AccessControl) RegisterEntity(entity *Entity) error {
+	if entity == nil {
+		return nil
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.entities == nil {
+		ac.entities = make(map[registryKey]*Entity)
+	}
+
+	key := registryKey{tenant: entity.Tenant, id: entity.ID}
+	if existing, ok := ac.entities[key]; ok {
+		if existing == entity {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrDuplicateEntity, key)
+	}
+
+	ac.entities[key] = entity
+	ac.Entities = append(ac.Entities, entity)
+	return nil
+}
+
+// RegisterEntities adds multiple entities, stopping at the first error.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	err := ac.RegisterEntities(permission.NewEntity("user1"), permission.NewEntity("user2"))
+func (ac *AccessControl) RegisterEntities(entities ...*Entity) error {
+	for _, entity := range entities {
+		if err := ac.RegisterEntity(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEntity finds a registered global entity by its ID, see GetEntityInTenant.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.CreateEntity("user1")
+//	user, err := ac.GetEntity("user1")
+func (ac *AccessControl) GetEntity(id string) (*Entity, error) {
+	return ac.GetEntityInTenant("", id)
+}
+
+// MustGetEntity is like GetEntity but panics when the entity does not exist.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.CreateEntity("user1")
+//	user := ac.MustGetEntity("user1")
+func (ac *AccessControl) MustGetEntity(id string) *Entity {
+	entity, err := ac.GetEntity(id)
+	if err != nil {
+		panic(err)
+	}
+	return entity
+}
+
 // Allow grants a specific permission to an entity for a given resource.
 //
 // Example:
@@ -79,6 +336,7 @@ func (ac *AccessControl) AddEntity(entity *Entity) *AccessControl {
 //	doc := ac.CreateResource("document")
 //	ac.Allow(user, doc, pe
 func (ac *AccessControl) Allow(entity *Entity, resource *Resource, permission Permission) *AccessControl {
+	ac.mustValidateGrant(entity, resource, permission)
 	entity.AddPerm(permission, resource, true)
 	return ac
 }
@@ -92,75 +350,192 @@ func (ac *AccessControl) Allow(entity *Entity, resource *Resource, permission Pe
 //	doc := ac.CreateResource("document")
 //	ac.Deny(user, doc, permission.Read)
 func (ac *AccessControl) Deny(entity *Entity, resource *Resource, permission Permission) *AccessControl {
+	ac.mustValidateGrant(entity, resource, permission)
 	entity.AddPerm(permission, resource, false)
 	return ac
 }
 
-// AddEntities adds multiple entities at once
+// AllowPattern grants a specific permission to an entity for every resource
+// matching the pattern, including resources created later.
 //
 // Example:
 //
 //	ac := permission.NewAccessControl()
-//	user1 := permission.NewEntity("user1")
-//	user2 := permission.NewEntity("user1")
-//	ac.AddEntities(user1, user2)
-func (ac *AccessControl) AddEntities(entities ...*Entity) {
-	ac.Entities = append(ac.Entities, entities...)
+//	user := ac.CreateEntity("user1")
+//	ac.AllowPattern(user, "web/comments/*", permission.Read)
+func (ac *AccessControl) AllowPattern(entity *Entity, pattern string, permission Permission) *AccessControl {
+	ac.mustValidatePermission(permission)
+	entity.AddPermPattern(permission, pattern, true)
+	return ac
 }
 
-// AddResources adds multiple resources at once.
+// DenyPattern denies a specific permission to an entity for every resource
+// matching the pattern. A rule for a concrete resource wins over a pattern rule
+// of the same entity on the same resource.
 //
 // Example:
 //
 //	ac := permission.NewAccessControl()
-//	res1 := permission.NewResource("res1")
-//	res2 := permission.NewResource("res2")
-//	ac.AddResources(res1, res2)
-func (ac *AccessControl) AddResources(resources ...*Resource) {
-	ac.Resources = append(ac.Resources, resources...)
+//	user := ac.CreateEntity("user1")
+//	ac.DenyPattern(user, "reports/2026-*", permission.Delete)
+func (ac *AccessControl) DenyPattern(entity *Entity, pattern string, permission Permission) *AccessControl {
+	ac.mustValidatePermission(permission)
+	entity.AddPermPattern(permission, pattern, false)
+	return ac
 }
 
-// HasPermission verifies if an entity has permission for a resource.
+// Revoke removes the rule of a specific permission of an entity for a given
+// resource, so the permission is inherited again.
 //
 // Example:
 //
 //	ac := permission.NewAccessControl()
 //	user := ac.CreateEntity("user1")
 //	doc := ac.CreateResource("document")
-//	ac.Allow(user, doc, permission.Read)
-//	fmt.Println(ac.HasPermission(user, doc, permission.Read)) // Output: true
-func (ac *AccessControl) HasPermission(entity *Entity, resource *Resource, permission Permission) bool {
-	for _, owner := range resource.Owners {
-		if owner == entity {
-			return true
-		}
+//	ac.Deny(user, doc, permission.Read)
+//	ac.Revoke(user, doc, permission.Read)
+func (ac *AccessControl) Revoke(entity *Entity, resource *Resource, permission Permission) *AccessControl {
+	entity.RemovePerm(permission, resource)
+	return ac
+}
+
+// RevokePattern removes the pattern rule of a specific permission of an entity.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	user := ac.CreateEntity("user1")
+//	ac.AllowPattern(user, "web/**", permission.Read)
+//	ac.RevokePattern(user, "web/**", permission.Read)
+func (ac *AccessControl) RevokePattern(entity *Entity, pattern string, permission Permission) *AccessControl {
+	entity.RevokePattern(pattern, permission)
+	return ac
+}
+
+// RemoveEntity unregisters an entity, disconnects it from its parents and
+// children and removes its ownership of registered resources.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	user := ac.CreateEntity("user1")
+//	ac.RemoveEntity(user)
+func (ac *AccessControl) RemoveEntity(entity *Entity) *AccessControl {
+	ac.mu.Lock()
+	key := registryKey{tenant: entity.Tenant, id: entity.ID}
+	if ac.entities[key] == entity {
+		delete(ac.entities, key)
 	}
+	ac.Entities = slices.DeleteFunc(ac.Entities, func(e *Entity) bool { return e == entity })
+	resources := collectResources(ac.Resources)
+	ac.mu.Unlock()
 
-	if perms, exists := entity.Permission[permission]; exists {
-		if val, ok := perms[resource]; ok {
-			return val
-		}
+	entity.RemoveParents(entity.GetParents()...)
+	entity.RemoveChildren(entity.GetChildren()...)
+	for _, resource := range resources {
+		resource.RemoveOwners(entity)
 	}
 
-	if perms, exists := entity.Permission[All]; exists {
-		if val, ok := perms[resource]; ok && val {
-			return true
+	return ac
+}
+
+// RemoveResource unregisters a resource together with its sub-resources,
+// detaches it from its parent and removes rules of all entities and roles for
+// them, together with role assignments scoped to them.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	doc := ac.CreateResource("document")
+//	ac.RemoveResource(doc)
+func (ac *AccessControl) RemoveResource(resource *Resource) *AccessControl {
+	removed := collectResources([]*Resource{resource})
+
+	hierarchyMu.Lock()
+	ac.mu.Lock()
+	unregistered := false
+	for _, r := range removed {
+		if !ac.registered[r] {
+			continue
 		}
+		unregistered = true
+		delete(ac.registered, r)
+		root := rootResource(r)
+		if root.registrations[ac]--; root.registrations[ac] <= 0 {
+			delete(root.registrations, ac)
+			ac.dropRoot(root)
+		}
+	}
+	if unregistered {
+		ac.Resources = slices.DeleteFunc(ac.Resources, func(r *Resource) bool { return !ac.registered[r] })
 	}
+	entities := collectEntities(ac.Entities)
+	ac.mu.Unlock()
+	hierarchyMu.Unlock()
 
-	for _, parent := range entity.Parents {
-		if ac.HasPermission(parent, resource, permission) {
-			return true
+	if parent := resource.GetParent(); parent != nil {
+		parent.RemoveSubs(resource)
+	}
+	for _, entity := range entities {
+		for _, r := range removed {
+			entity.RevokeResource(r)
+		}
+		for _, binding := range entity.GetRoles() {
+			if slices.Contains(removed, binding.Scope) {
+				entity.UnassignRole(binding.Role, binding.Scope)
+			}
 		}
 	}
-
-	if resource.Parent != nil {
-		if ac.HasPermission(entity, resource.Parent, permission) {
-			return true
+	for _, role := range ac.Roles() {
+		for granted, permissions := range role.GetGrants() {
+			if slices.Contains(removed, granted) {
+				role.RevokeOn(granted, permissions...)
+			}
 		}
 	}
 
-	return false
+	return ac
+}
+
+// AddEntities adds multiple entities at once, panicking on duplicate IDs.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	user1 := permission.NewEntity("user1")
+//	user2 := permission.NewEntity("user2")
+//	ac.AddEntities(user1, user2)
+func (ac *AccessControl) AddEntities(entities ...*Entity) {
+	for _, entity := range entities {
+		ac.AddEntity(entity)
+	}
+}
+
+// AddResources adds multiple resources at once, panicking on duplicate paths.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	res1 := permission.NewResource("res1")
+//	res2 := permission.NewResource("res2")
+//	ac.AddResources(res1, res2)
+func (ac *AccessControl) AddResources(resources ...*Resource) {
+	for _, resource := range resources {
+		ac.AddResource(resource)
+	}
+}
+
+// HasPermission verifies if an entity has permission for a resource.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	user := ac.CreateEntity("user1")
+//	doc := ac.CreateResource("document")
+//	ac.Allow(user, doc, permission.Read)
+//	fmt.Println(ac.HasPermission(user, doc, permission.Read)) // Output: true
+func (ac *AccessControl) HasPermission(entity *Entity, resource *Resource, permission Permission) bool {
+	return ac.Explain(entity, resource, permission).Allowed
 }
 
 // Can checks if an entity has a specific permission for a resource.
@@ -0,0 +1,180 @@
+package permission
+
+import (
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Attributes holds metadata of an entity or resource, like a department or a
+// classification level, for conditions and expressions. The zero value is
+// empty and ready to use, methods are safe for concurrent use.
+//
+// Example:
+//
+//	user := permission.NewEntity("alice")
+//	user.Attributes.Set("department", "sales")
+//	department, ok := user.Attributes.String("department")
+type Attributes struct {
+	mu     sync.RWMutex
+	values map[string]any
+}
+
+// Set sets the value of an attribute.
+//
+// Example:
+//
+//	doc := permission.NewResource("report")
+//	doc.Attributes.Set("level", 3)
+func (a *Attributes) Set(key string, value any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.values == nil {
+		a.values = make(map[string]any)
+	}
+	a.values[key] = value
+}
+
+// Get returns the value of an attribute and whether it is set.
+//
+// Example:
+//
+//	value, ok := doc.Attributes.Get("level")
+func (a *Attributes) Get(key string) (any, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	value, ok := a.values[key]
+	return value, ok
+}
+
+// Has reports whether an attribute is set.
+//
+// Example:
+//
+//	doc.Attributes.Has("level") // true
+func (a *Attributes) Has(key string) bool {
+	_, ok := a.Get(key)
+	return ok
+}
+
+// Delete removes an attribute.
+//
+// Example:
+//
+//	doc.Attributes.Delete("level")
+func (a *Attributes) Delete(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.values, key)
+}
+
+// Keys returns the names of set attributes in sorted order.
+//
+// Example:
+//
+//	doc.Attributes.Keys() // [classification level]
+func (a *Attributes) Keys() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return slices.Sorted(maps.Keys(a.values))
+}
+
+// Map returns a copy of all attributes, nil when none is set.
+//
+// Example:
+//
+//	for key, value := range doc.Attributes.Map() {
+//		fmt.Println(key, value)
+//	}
+func (a *Attributes) Map() map[string]any {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if len(a.values) == 0 {
+		return nil
+	}
+	return maps.Clone(a.values)
+}
+
+// String returns a string attribute, ok is false when the attribute is not
+// set or is not a string.
+//
+// Example:
+//
+//	department, ok := user.Attributes.String("department")
+func (a *Attributes) String(key string) (string, bool) {
+	value, _ := a.Get(key)
+	s, ok := normalize(value).(string)
+	return s, ok
+}
+
+// Int returns an integer attribute. Floating point values without a fraction,
+// like numbers decoded from JSON, are accepted.
+//
+// Example:
+//
+//	level, ok := doc.Attributes.Int("level")
+func (a *Attributes) Int(key string) (int, bool) {
+	f, ok := a.Float(key)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// Float returns a numeric attribute as float64.
+//
+// Example:
+//
+//	score, ok := user.Attributes.Float("score")
+func (a *Attributes) Float(key string) (float64, bool) {
+	value, _ := a.Get(key)
+	f, ok := normalize(value).(float64)
+	return f, ok
+}
+
+// Bool returns a boolean attribute.
+//
+// Example:
+//
+//	verified, ok := user.Attributes.Bool("verified")
+func (a *Attributes) Bool(key string) (bool, bool) {
+	value, _ := a.Get(key)
+	b, ok := normalize(value).(bool)
+	return b, ok
+}
+
+// Strings returns a list attribute whose items are all strings.
+//
+// Example:
+//
+//	user.Attributes.Set("regions", []string{"eu", "us"})
+//	regions, ok := user.Attributes.Strings("regions")
+func (a *Attributes) Strings(key string) ([]string, bool) {
+	value, _ := a.Get(key)
+	list, ok := normalize(value).([]any)
+	if !ok {
+		return nil, false
+	}
+
+	strings := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		strings = append(strings, s)
+	}
+	return strings, true
+}
+
+// attrEquals reports whether an attribute is set to the value, numbers are
+// equal regardless of their Go type.
+func (a *Attributes) attrEquals(key string, value any) bool {
+	actual, ok := a.Get(key)
+	return ok && reflect.DeepEqual(normalize(actual), normalize(value))
+}
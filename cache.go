@@ -0,0 +1,262 @@
+package permission
+
+import (
+	"slices"
+	"sync"
+)
+
+// DefaultDecisionCacheSize is the number of decisions the decision cache holds
+// unless set with WithDecisionCacheSize.
+const DefaultDecisionCacheSize = 10000
+
+// CacheStats counts lookups of the decision cache.
+type CacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// decisionKey identifies a cached decision.
+type decisionKey struct {
+	entity     *Entity
+	resource   *Resource
+	permission Permission
+}
+
+// entityVersion, resourceVersion and roleVersion record the state of a node a
+// decision was computed from. Tenants, IDs and types are recorded too, as they
+// are changed by assigning fields.
+type entityVersion struct {
+	entity  *Entity
+	version uint64
+	tenant  string
+}
+
+type resourceVersion struct {
+	resource     *Resource
+	version      uint64
+	id           string
+	tenant       string
+	resourceType string
+}
+
+type roleVersion struct {
+	role    *Role
+	version uint64
+}
+
+// snapshot records what a decision depends on: the settings of the
+// AccessControl, the checked entity and resource with their ancestors and the
+// roles assigned to them. Versions are read before the state they guard, so a
+// concurrent change always makes the snapshot stale.
+type snapshot struct {
+	generation uint64
+	entities   []entityVersion
+	resources  []resourceVersion
+	roles      []roleVersion
+}
+
+// entity records the entity and its assigned roles.
+func (s *snapshot) entity(entity *Entity) {
+	s.entities = append(s.entities, entityVersion{entity: entity, version: entity.version.Load(), tenant: entity.Tenant})
+	for _, binding := range entity.GetRoles() {
+		s.roles = append(s.roles, roleVersion{role: binding.Role, version: binding.Role.version.Load()})
+	}
+}
+
+// resource records the resource.
+func (s *snapshot) resource(resource *Resource) {
+	s.resources = append(s.resources, resourceVersion{
+		resource:     resource,
+		version:      resource.version.Load(),
+		id:           resource.ID,
+		tenant:       resource.Tenant,
+		resourceType: resource.Type,
+	})
+}
+
+// current reports whether nothing recorded changed since.
+func (s *snapshot) current(generation uint64) bool {
+	if s.generation != generation {
+		return false
+	}
+	for _, e := range s.entities {
+		if e.entity.version.Load() != e.version || e.entity.Tenant != e.tenant {
+			return false
+		}
+	}
+	for _, r := range s.resources {
+		if r.resource.version.Load() != r.version || r.resource.ID != r.id || r.resource.Tenant != r.tenant || r.resource.Type != r.resourceType {
+			return false
+		}
+	}
+	for _, r := range s.roles {
+		if r.role.version.Load() != r.version {
+			return false
+		}
+	}
+	return true
+}
+
+// cachedDecision is a decision with the snapshot it was computed from.
+type cachedDecision struct {
+	decision Decision
+	versions snapshot
+}
+
+// decisionCache memoizes decisions of checks which involve no conditions.
+type decisionCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[decisionKey]cachedDecision
+	hits    uint64
+	misses  uint64
+}
+
+func newDecisionCache(size int) *decisionCache {
+	if size <= 0 {
+		size = DefaultDecisionCacheSize
+	}
+	return &decisionCache{size: size}
+}
+
+// get returns the cached decision unless it is missing or stale, a stale one
+// is dropped.
+func (c *decisionCache) get(key decisionKey, generation uint64) (Decision, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && !entry.versions.current(generation) {
+		delete(c.entries, key)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return Decision{}, false
+	}
+	c.hits++
+	decision := entry.decision
+	decision.Trace = slices.Clone(decision.Trace)
+	return decision, true
+}
+
+// put stores a decision, evicting an arbitrary one when the cache is full.
+func (c *decisionCache) put(key decisionKey, decision Decision, versions snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[decisionKey]cachedDecision)
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		for evicted := range c.entries {
+			delete(c.entries, evicted)
+			break
+		}
+	}
+	decision.Trace = slices.Clone(decision.Trace)
+	c.entries[key] = cachedDecision{decision: decision, versions: versions}
+}
+
+// WithDecisionCache enables the decision cache, see SetDecisionCache.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl(permission.WithDecisionCache())
+func WithDecisionCache() Option {
+	return WithDecisionCacheSize(DefaultDecisionCacheSize)
+}
+
+// WithDecisionCacheSize enables the decision cache holding at most size
+// decisions, an arbitrary one is evicted when it is full.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl(permission.WithDecisionCacheSize(1000))
+func WithDecisionCacheSize(size int) Option {
+	return func(ac *AccessControl) {
+		ac.cache = newDecisionCache(size)
+	}
+}
+
+// SetDecisionCache enables or disables memoizing decisions of checks. A cached
+// decision is dropped once the checked entity or resource, their ancestors,
+// the roles assigned to them or settings of the AccessControl change through
+// methods, or once a tenant, ID or type of them is assigned. Other fields must
+// not be modified directly while the cache is enabled. Checks involving rules
+// with conditions, including time-bound grants, and failed checks are never
+// cached.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.SetDecisionCache(true)
+//	ac.CanRead(user, doc)
+//	ac.CanRead(user, doc) // cached
+//	fmt.Println(ac.CacheStats()) // {1 1 1}
+func (ac *AccessControl) SetDecisionCache(enabled bool) *AccessControl {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	switch {
+	case !enabled:
+		ac.cache = nil
+	case ac.cache == nil:
+		ac.cache = newDecisionCache(DefaultDecisionCacheSize)
+	}
+	return ac
+}
+
+// CacheStats returns the hits, misses and current entries of the decision
+// cache, zero when the cache is disabled.
+//
+// Example:
+//
+//	stats := ac.CacheStats()
+//	fmt.Printf("hit rate %.2f\n", float64(stats.Hits)/float64(stats.Hits+stats.Misses))
+func (ac *AccessControl) CacheStats() CacheStats {
+	cache := ac.decisionCache()
+	if cache == nil {
+		return CacheStats{}
+	}
+
+	generation := ac.generation.Load()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entries := 0
+	for _, entry := range cache.entries {
+		if entry.versions.current(generation) {
+			entries++
+		}
+	}
+	return CacheStats{Hits: cache.hits, Misses: cache.misses, Entries: entries}
+}
+
+// decisionCache returns the decision cache, nil when it is disabled.
+func (ac *AccessControl) decisionCache() *decisionCache {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.cache
+}
+
+// touch invalidates cached decisions depending on settings of the
+// AccessControl, it must be called after the change.
+func (ac *AccessControl) touch() {
+	ac.generation.Add(1)
+}
+
+// touch invalidates cached decisions depending on the entity.
+func (e *Entity) touch() {
+	e.version.Add(1)
+}
+
+// touch invalidates cached decisions depending on the resource.
+func (r *Resource) touch() {
+	r.version.Add(1)
+}
+
+// touch invalidates cached decisions depending on the role.
+func (r *Role) touch() {
+	r.version.Add(1)
+}
@@ -0,0 +1,188 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"time"
+)
+
+// Request describes the permission check a Condition is evaluated for.
+type Request struct {
+	// Subject is the checked entity, not the ancestor holding the rule.
+	Subject *Entity
+	// Resource is the checked resource, not the ancestor the rule is set for.
+	Resource   *Resource
+	Permission Permission
+	// Attributes are attributes of the request passed to CanWithContext,
+	// like the client IP address.
+	Attributes map[string]any
+	// Time is the time of the check.
+	Time time.Time
+}
+
+// Condition restricts a rule, the rule applies only when all its conditions
+// are met. A failing condition never allows: an allow rule with a condition
+// returning an error does not apply, a deny rule does.
+type Condition interface {
+	Evaluate(ctx context.Context, request *Request) (bool, error)
+}
+
+// ConditionFunc adapts a function to a Condition.
+//
+// Example:
+//
+//	draft := permission.ConditionFunc(func(ctx context.Context, r *permission.Request) (bool, error) {
+//		return r.Attributes["status"] == "draft", nil
+//	})
+type ConditionFunc func(ctx context.Context, request *Request) (bool, error)
+
+// Evaluate calls f(ctx, request).
+func (f ConditionFunc) Evaluate(ctx context.Context, request *Request) (bool, error) {
+	return f(ctx, request)
+}
+
+// RequestAttrEquals is met when the request attribute equals the value.
+//
+// Example:
+//
+//	ac.AllowIf(editors, articles, permission.Update, permission.RequestAttrEquals("status", "draft"))
+func RequestAttrEquals(key string, value any) Condition {
+	return ConditionFunc(func(ctx context.Context, request *Request) (bool, error) {
+		actual, ok := request.Attributes[key]
+		return ok && reflect.DeepEqual(actual, value), nil
+	})
+}
+
+// SubjectAttrEquals is met when the attribute of the checked entity equals
+// the value.
+//
+// Example:
+//
+//	ac.AllowIf(staff, reports, permission.Read, permission.SubjectAttrEquals("department", "sales"))
+func SubjectAttrEquals(key string, value any) Condition {
+	return ConditionFunc(func(ctx context.Context, request *Request) (bool, error) {
+		return request.Subject.Attributes.attrEquals(key, value), nil
+	})
+}
+
+// ResourceAttrEquals is met when the attribute of the checked resource equals
+// the value.
+//
+// Example:
+//
+//	ac.DenyIf(contractors, documents, permission.Read, permission.ResourceAttrEquals("classification", "secret"))
+func ResourceAttrEquals(key string, value any) Condition {
+	return ConditionFunc(func(ctx context.Context, request *Request) (bool, error) {
+		return request.Resource.Attributes.attrEquals(key, value), nil
+	})
+}
+
+// RequestIPIn is met when the request attribute holds an IP address (string,
+// net.IP or netip.Addr) within one of the prefixes.
+//
+// Example:
+//
+//	office := permission.RequestIPIn("ip", netip.MustParsePrefix("10.0.0.0/8"))
+//	ac.AllowIf(users, reports, permission.Read, office)
+func RequestIPIn(key string, prefixes ...netip.Prefix) Condition {
+	return ConditionFunc(func(ctx context.Context, request *Request) (bool, error) {
+		var addr netip.Addr
+		switch value := request.Attributes[key].(type) {
+		case nil:
+			return false, nil
+		case netip.Addr:
+			addr = value
+		case net.IP:
+			parsed, ok := netip.AddrFromSlice(value)
+			if !ok {
+				return false, fmt.Errorf("%w: %s is not an IP address", ErrCondition, key)
+			}
+			addr = parsed
+		case string:
+			parsed, err := netip.ParseAddr(value)
+			if err != nil {
+				return false, fmt.Errorf("%w: %s: %v", ErrCondition, key, err)
+			}
+			addr = parsed
+		default:
+			return false, fmt.Errorf("%w: %s is not an IP address", ErrCondition, key)
+		}
+
+		addr = addr.Unmap()
+		for _, prefix := range prefixes {
+			if prefix.Contains(addr) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// CanWithContext checks if an entity has a specific permission for a resource,
+// evaluating conditions of rules with the request attributes.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	user := ac.CreateEntity("user1")
+//	doc := ac.CreateResource("document")
+//	ac.AllowIf(user, doc, permission.Read, permission.RequestAttrEquals("channel", "web"))
+//	ac.CanWithContext(ctx, user, doc, permission.Read, map[string]any{"channel": "web"}) // true
+func (ac *AccessControl) CanWithContext(ctx context.Context, entity *Entity, resource *Resource, permission Permission, attributes map[string]any) bool {
+	return ac.ExplainWithContext(ctx, entity, resource, permission, attributes).Allowed
+}
+
+// AllowIf grants a specific permission to an entity for a given resource when
+// all conditions are met.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	editors := ac.CreateEntity("editors")
+//	articles := ac.CreateResource("articles")
+//	ac.AllowIf(editors, articles, permission.Update, permission.RequestAttrEquals("status", "draft"))
+func (ac *AccessControl) AllowIf(entity *Entity, resource *Resource, permission Permission, conditions ...Condition) *AccessControl {
+	ac.mustValidateGrant(entity, resource, permission)
+	entity.AddPermIf(permission, resource, true, conditions...)
+	return ac
+}
+
+// DenyIf denies a specific permission to an entity for a given resource when
+// all conditions are met.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	users := ac.CreateEntity("users")
+//	reports := ac.CreateResource("reports")
+//	ac.DenyIf(users, reports, permission.Read, permission.RequestAttrEquals("vpn", false))
+func (ac *AccessControl) DenyIf(entity *Entity, resource *Resource, permission Permission, conditions ...Condition) *AccessControl {
+	ac.mustValidateGrant(entity, resource, permission)
+	entity.AddPermIf(permission, resource, false, conditions...)
+	return ac
+}
+
+// ruleKey identifies a rule of an entity for a resource or a pattern.
+type ruleKey struct {
+	permission Permission
+	resource   *Resource
+	pattern    string
+}
+
+// applies evaluates the conditions of a rule. It reports whether the rule
+// applies and the error of a failed condition.
+func (rule *Rule) applies(ctx context.Context, request *Request) (bool, error) {
+	for _, condition := range rule.Conditions {
+		met, err := condition.Evaluate(ctx, request)
+		if err != nil {
+			return !rule.Allow, err
+		}
+		if !met {
+			return false, nil
+		}
+	}
+	return true, nil
+}
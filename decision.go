@@ -0,0 +1,367 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Rule is a single permission setting of an entity for a resource.
+type Rule struct {
+	Entity   *Entity
+	Resource *Resource
+	// Pattern is set for rules of resource patterns, Resource is then the matched resource.
+	Pattern string
+	// Role is set for rules of an assigned role, Resource is then the scope of
+	// the assignment, the granted resource or the root of an unscoped one.
+	Role       *Role
+	Permission Permission
+	Allow      bool
+	// Conditions must all be met for the rule to apply.
+	Conditions []Condition
+}
+
+func (r Rule) String() string {
+	verb := "deny"
+	if r.Allow {
+		verb = "allow"
+	}
+
+	target := r.Pattern
+	switch {
+	case r.Pattern == "":
+		target = r.Resource.Path()
+	case r.Resource != nil:
+		target = fmt.Sprintf("%s (%s)", r.Pattern, r.Resource.Path())
+	}
+	if r.Role != nil {
+		target += " by role " + r.Role.ID
+	}
+	if len(r.Conditions) > 0 {
+		return fmt.Sprintf("%s %s for %s on %s if conditions are met", verb, r.Permission, r.Entity.ID, target)
+	}
+	return fmt.Sprintf("%s %s for %s on %s", verb, r.Permission, r.Entity.ID, target)
+}
+
+// Decision describes the outcome of a permission check and how it was reached.
+type Decision struct {
+	Allowed bool
+	// Rule is the rule which decided, nil when ownership decided or no rule matched.
+	Rule *Rule
+	// Owner is true when the check was short-circuited by ownership.
+	Owner bool
+	// Entity is the checked entity or the ancestor the deciding rule or ownership came from.
+	Entity *Entity
+	// Resource is the checked resource or the ancestor the deciding rule or ownership is attached to.
+	Resource *Resource
+	// Trace lists every applicable rule, in evaluation order.
+	Trace []Rule
+	// Err joins errors of conditions which could not be evaluated.
+	Err error
+}
+
+func (d Decision) String() string {
+	result := "denied"
+	if d.Allowed {
+		result = "allowed"
+	}
+
+	switch {
+	case d.Owner:
+		return fmt.Sprintf("%s: %s owns %s", result, d.Entity.ID, d.Resource.Path())
+	case d.Rule != nil:
+		return fmt.Sprintf("%s: %s", result, d.Rule)
+	default:
+		return fmt.Sprintf("%s: no matching rule", result)
+	}
+}
+
+// Explain evaluates a permission check like HasPermission and returns the
+// decision together with the rule, ancestor entity and ancestor resource it
+// came from.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	group := ac.CreateEntity("group")
+//	user := group.CreateChild("user")
+//	doc := ac.CreateResource("document")
+//	ac.Allow(group, doc, permission.Read)
+//	fmt.Println(ac.Explain(user, doc, permission.Read)) // Output: allowed: allow READ for group on document
+func (ac *AccessControl) Explain(entity *Entity, resource *Resource, permission Permission) Decision {
+	return ac.ExplainWithContext(context.Background(), entity, resource, permission, nil)
+}
+
+// ExplainWithContext is like Explain, evaluating conditions of rules with the
+// request attributes.
+//
+// Example:
+//
+//	decision := ac.ExplainWithContext(ctx, user, doc, permission.Read, map[string]any{"ip": "10.0.0.1"})
+func (ac *AccessControl) ExplainWithContext(ctx context.Context, entity *Entity, resource *Resource, permission Permission, attributes map[string]any) Decision {
+	cache := ac.decisionCache()
+	if cache == nil {
+		decision, _ := ac.explain(ctx, entity, resource, permission, attributes, nil)
+		return decision
+	}
+
+	key := decisionKey{entity: entity, resource: resource, permission: permission}
+	if decision, ok := cache.get(key, ac.generation.Load()); ok {
+		return decision
+	}
+	versions := &snapshot{generation: ac.generation.Load()}
+	decision, ev := ac.explain(ctx, entity, resource, permission, attributes, versions)
+	if decision.Err == nil && !ev.conditional {
+		cache.put(key, decision, *versions)
+	}
+	return decision
+}
+
+// explain evaluates a permission check, returning the evaluation state too.
+// Visited entities, resources and roles are recorded in versions unless it is
+// nil.
+func (ac *AccessControl) explain(ctx context.Context, entity *Entity, resource *Resource, permission Permission, attributes map[string]any, versions *snapshot) (Decision, *evaluation) {
+	ev := newEvaluation(entity, resource, permission, versions)
+	ev.permissions = ac.implying(permission)
+	ev.ctx = ctx
+	ev.request = &Request{Subject: entity, Resource: resource, Permission: permission, Attributes: attributes, Time: ac.now()}
+	decision := Decision{Entity: entity, Resource: resource}
+
+	if err := crossTenantError(entity, resource); err != nil {
+		decision.Err = err
+		return decision, ev
+	}
+	if err := ac.strictPermissionError(permission); err != nil {
+		decision.Err = err
+		return decision, ev
+	}
+	if err := ac.inapplicableError(resource, permission); err != nil {
+		decision.Err = err
+		return decision, ev
+	}
+
+	strategy := ac.Strategy()
+	if strategy != AnyPathAllows {
+		if owner, owned, ok := ev.ownership(); ok {
+			return ownedDecision(decision, owner, owned), ev
+		}
+	}
+
+	candidates := ev.candidates()
+	for _, c := range candidates {
+		decision.Trace = append(decision.Trace, c.rule)
+	}
+	decision.Err = errors.Join(ev.errors...)
+
+	var winner *candidate
+	if strategy == AnyPathAllows {
+		var owner *Entity
+		var owned *Resource
+		if winner, owner, owned = ev.anyPathAllows(candidates); owner != nil {
+			return ownedDecision(decision, owner, owned), ev
+		}
+	} else {
+		winner = strategy.resolve(candidates)
+	}
+	if winner != nil {
+		rule := winner.rule
+		decision.Allowed = rule.Allow
+		decision.Rule = &rule
+		decision.Entity = rule.Entity
+		decision.Resource = rule.Resource
+	}
+
+	return decision, ev
+}
+
+// ownedDecision allows by the ownership of the owned resource.
+func ownedDecision(decision Decision, owner *Entity, owned *Resource) Decision {
+	decision.Allowed = true
+	decision.Owner = true
+	decision.Entity = owner
+	decision.Resource = owned
+	return decision
+}
+
+// candidate is an applicable rule together with the distance of its entity
+// and resource from the checked ones.
+type candidate struct {
+	rule          Rule
+	entityDepth   int
+	resourceDepth int
+	// implying is set for allows of a permission implying the checked one,
+	// other than All.
+	implying bool
+}
+
+// moreSpecific reports whether c comes from a nearer entity, or from the same
+// distance but a nearer resource, than other. For the same entity and
+// resource, a rule for the checked permission is more specific than a rule
+// for a permission implying it, and a rule for the concrete resource is more
+// specific than a pattern rule, which is more specific than a role rule.
+func (c candidate) moreSpecific(other candidate) bool {
+	if c.entityDepth != other.entityDepth {
+		return c.entityDepth < other.entityDepth
+	}
+	if c.resourceDepth != other.resourceDepth {
+		return c.resourceDepth < other.resourceDepth
+	}
+	if c.implying != other.implying {
+		return other.implying
+	}
+	return c.rule.kind() < other.rule.kind()
+}
+
+// kind orders rules of the same entity and resource: concrete, pattern, role.
+func (r Rule) kind() int {
+	switch {
+	case r.Role != nil:
+		return 2
+	case r.Pattern != "":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// leveledEntity is the checked entity or one of its ancestors with its distance.
+type leveledEntity struct {
+	entity *Entity
+	depth  int
+}
+
+// evaluation holds the state of a single permission check.
+type evaluation struct {
+	permission Permission
+	// permissions are the checked permission followed by permissions implying it.
+	permissions []Permission
+	entities    []leveledEntity
+	resources   []*Resource
+	paths       []string
+	tenant      string
+
+	ctx     context.Context
+	request *Request
+	errors  []error
+	// conditional is set when a rule with conditions was considered.
+	conditional bool
+}
+
+// newEvaluation collects the ancestors of entity (breadth-first) and resource,
+// visiting every node once, so hierarchies containing cycles terminate. The
+// visited nodes are recorded in versions unless it is nil.
+func newEvaluation(entity *Entity, resource *Resource, permission Permission, versions *snapshot) *evaluation {
+	ev := &evaluation{
+		permission: permission,
+		ctx:        context.Background(),
+		request:    &Request{Subject: entity, Resource: resource, Permission: permission, Time: time.Now()},
+	}
+
+	visitedEntities := map[*Entity]bool{entity: true}
+	ev.entities = append(ev.entities, leveledEntity{entity: entity})
+	for i := 0; i < len(ev.entities); i++ {
+		current := ev.entities[i]
+		if versions != nil {
+			versions.entity(current.entity)
+		}
+		for _, parent := range current.entity.GetParents() {
+			if !visitedEntities[parent] {
+				visitedEntities[parent] = true
+				ev.entities = append(ev.entities, leveledEntity{entity: parent, depth: current.depth + 1})
+			}
+		}
+	}
+
+	visitedResources := make(map[*Resource]bool)
+	for current := resource; current != nil && !visitedResources[current]; current = current.GetParent() {
+		visitedResources[current] = true
+		if versions != nil {
+			versions.resource(current)
+		}
+		ev.resources = append(ev.resources, current)
+	}
+	for _, current := range ev.resources {
+		ev.paths = append(ev.paths, current.Path())
+		if ev.tenant == "" {
+			ev.tenant = current.Tenant
+		}
+	}
+
+	return ev
+}
+
+// ownership finds the entity or ancestor owning the resource or its ancestor.
+func (ev *evaluation) ownership() (*Entity, *Resource, bool) {
+	for _, resource := range ev.resources {
+		for _, e := range ev.entities {
+			if resource.isOwner(e.entity) {
+				return e.entity, resource, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// candidates lists applicable rules in evaluation order, skipping rules whose
+// conditions are not met. For each entity and
+// resource, rules for the concrete resource come before pattern rules and
+// rules of assigned roles. Rules for the checked permission come before allows
+// of permissions implying it, denies of them do not deny the implied
+// permissions. A deny of All comes before the rule for the
+// checked permission, so it bans the entity from the resource, an allow of All
+// comes after it.
+func (ev *evaluation) candidates() []candidate {
+	permissions := ev.permissions
+	var candidates []candidate
+	for _, e := range ev.entities {
+		for depth, resource := range ev.resources {
+			concrete, patterns := e.entity.matchingRules(permissions, resource, ev.paths[depth])
+			if ev.tenant != "" && e.entity.Tenant != ev.tenant {
+				// Rules set bypassing ValidateGrant never cross tenants.
+				concrete = nil
+			}
+			roles := e.entity.roleRules(permissions, ev.resources, depth)
+			for _, rules := range [][]Rule{concrete, patterns, roles} {
+				for _, rule := range orderRules(rules) {
+					implying := rule.Permission != ev.permission && rule.Permission != All
+					if implying && !rule.Allow {
+						continue
+					}
+					if len(rule.Conditions) > 0 {
+						ev.conditional = true
+					}
+					applies, err := rule.applies(ev.ctx, ev.request)
+					if err != nil {
+						ev.errors = append(ev.errors, fmt.Errorf("%s: %w", rule, err))
+					}
+					if applies {
+						candidates = append(candidates, candidate{rule: rule, entityDepth: e.depth, resourceDepth: depth, implying: implying})
+					}
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// orderRules moves denies of All before and allows of All after other rules.
+func orderRules(rules []Rule) []Rule {
+	ordered := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Permission == All && !rule.Allow {
+			ordered = append(ordered, rule)
+		}
+	}
+	for _, rule := range rules {
+		if rule.Permission != All {
+			ordered = append(ordered, rule)
+		}
+	}
+	for _, rule := range rules {
+		if rule.Permission == All && rule.Allow {
+			ordered = append(ordered, rule)
+		}
+	}
+	return ordered
+}
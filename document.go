@@ -0,0 +1,845 @@
+package permission
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// document is the serialized form of an AccessControl graph.
+type document struct {
+	Strategy    Strategy             `json:"strategy,omitempty" yaml:"strategy"`
+	Strict      bool                 `json:"strict,omitempty" yaml:"strict"`
+	Permissions []permissionDocument `json:"permissions,omitempty" yaml:"permissions"`
+	// Implies maps permissions to the permissions they imply, Sets maps names
+	// of permission sets to their members.
+	Implies   map[Permission][]Permission `json:"implies,omitempty" yaml:"implies"`
+	Sets      map[Permission][]Permission `json:"sets,omitempty" yaml:"sets"`
+	Types     []typeDocument              `json:"types,omitempty" yaml:"types"`
+	Resources []resourceDocument          `json:"resources,omitempty" yaml:"resources"`
+	Roles     []roleDocument              `json:"roles,omitempty" yaml:"roles"`
+	Entities  []entityDocument            `json:"entities,omitempty" yaml:"entities"`
+	// Relations maps object types to rewrites of their relations, Tuples
+	// lists relation tuples in the object#relation@subject form.
+	Relations map[string]map[string][]string `json:"relations,omitempty" yaml:"relations"`
+	Tuples    []string                       `json:"tuples,omitempty" yaml:"tuples"`
+}
+
+// permissionDocument is a declared permission, encoded as its name when it has
+// no description and category.
+type permissionDocument struct {
+	Name        Permission `json:"name" yaml:"name"`
+	Description string     `json:"description,omitempty" yaml:"description"`
+	Category    string     `json:"category,omitempty" yaml:"category"`
+}
+
+func (doc permissionDocument) MarshalJSON() ([]byte, error) {
+	if doc.Description == "" && doc.Category == "" {
+		return json.Marshal(doc.Name)
+	}
+	type plain permissionDocument
+	return json.Marshal(plain(doc))
+}
+
+func (doc *permissionDocument) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*doc = permissionDocument{}
+		return json.Unmarshal(data, &doc.Name)
+	}
+	type plain permissionDocument
+	return json.Unmarshal(data, (*plain)(doc))
+}
+
+// typeDocument is a resource type with its applicable permissions and relations.
+type typeDocument struct {
+	Name        string       `json:"name" yaml:"name"`
+	Permissions []Permission `json:"permissions,omitempty" yaml:"permissions"`
+	Relations   []string     `json:"relations,omitempty" yaml:"relations"`
+}
+
+// resourceDocument is a resource with its sub-resources. Owners are entity IDs.
+type resourceDocument struct {
+	ID         string             `json:"id" yaml:"id"`
+	Tenant     string             `json:"tenant,omitempty" yaml:"tenant"`
+	Type       string             `json:"type,omitempty" yaml:"type"`
+	Attributes map[string]any     `json:"attributes,omitempty" yaml:"attributes"`
+	Owners     []string           `json:"owners,omitempty" yaml:"owners"`
+	Resources  []resourceDocument `json:"resources,omitempty" yaml:"resources"`
+
+	line int
+}
+
+// entityDocument is an entity with its parent IDs and rules keyed by
+// permission, listing resource paths or patterns. Conditional rules are
+// listed separately.
+type entityDocument struct {
+	ID         string                  `json:"id" yaml:"id"`
+	Tenant     string                  `json:"tenant,omitempty" yaml:"tenant"`
+	Attributes map[string]any          `json:"attributes,omitempty" yaml:"attributes"`
+	Parents    []string                `json:"parents,omitempty" yaml:"parents"`
+	Allow      map[Permission][]string `json:"allow,omitempty" yaml:"allow"`
+	Deny       map[Permission][]string `json:"deny,omitempty" yaml:"deny"`
+	Rules      []ruleDocument          `json:"rules,omitempty" yaml:"rules"`
+	Roles      []bindingDocument       `json:"roles,omitempty" yaml:"roles"`
+
+	line int
+}
+
+// roleDocument is a role with its resource-independent permissions and
+// permissions granted on resource paths, TenantGrants holds grants on
+// resources of tenants by tenant.
+type roleDocument struct {
+	ID           string                             `json:"id" yaml:"id"`
+	Permissions  []Permission                       `json:"permissions,omitempty" yaml:"permissions"`
+	Grants       map[Permission][]string            `json:"grants,omitempty" yaml:"grants"`
+	TenantGrants map[string]map[Permission][]string `json:"tenantGrants,omitempty" yaml:"tenantGrants"`
+
+	line int
+}
+
+// bindingDocument is an assignment of a role ID within a scope path, an empty
+// scope means every resource.
+type bindingDocument struct {
+	Role  string `json:"role" yaml:"role"`
+	Scope string `json:"scope,omitempty" yaml:"scope"`
+}
+
+// ruleDocument is a single allow or deny rule for a resource path or pattern,
+// applying when the If expression is met, within the validity period and the
+// schedule.
+type ruleDocument struct {
+	Allow     Permission        `json:"allow,omitempty" yaml:"allow"`
+	Deny      Permission        `json:"deny,omitempty" yaml:"deny"`
+	Resource  string            `json:"resource" yaml:"resource"`
+	If        string            `json:"if,omitempty" yaml:"if"`
+	NotBefore *time.Time        `json:"notBefore,omitempty" yaml:"notBefore"`
+	NotAfter  *time.Time        `json:"notAfter,omitempty" yaml:"notAfter"`
+	Schedule  *scheduleDocument `json:"schedule,omitempty" yaml:"schedule"`
+
+	line int
+}
+
+// document exports the graph of registered entities and resources, together
+// with every entity and resource reachable from them.
+func (ac *AccessControl) document() (*document, error) {
+	ac.mu.RLock()
+	doc := &document{Strategy: ac.strategy, Strict: ac.strict}
+	for _, info := range ac.declared {
+		doc.Permissions = append(doc.Permissions, permissionDocument{Name: info.Permission, Description: info.Description, Category: info.Category})
+	}
+	for permission, implied := range ac.implications {
+		if ac.sets[permission] {
+			if doc.Sets == nil {
+				doc.Sets = make(map[Permission][]Permission)
+			}
+			doc.Sets[permission] = slices.Clone(implied)
+			continue
+		}
+		if doc.Implies == nil {
+			doc.Implies = make(map[Permission][]Permission)
+		}
+		doc.Implies[permission] = slices.Clone(implied)
+	}
+	for _, resourceType := range ac.types {
+		doc.Types = append(doc.Types, typeDocument{Name: resourceType.Name, Permissions: slices.Clone(resourceType.Permissions), Relations: slices.Clone(resourceType.Relations)})
+	}
+	sort.Slice(doc.Types, func(i, j int) bool { return doc.Types[i].Name < doc.Types[j].Name })
+	for objectType, relations := range ac.relations {
+		if doc.Relations == nil {
+			doc.Relations = make(map[string]map[string][]string)
+		}
+		doc.Relations[objectType] = make(map[string][]string, len(relations))
+		for relation, rewrites := range relations {
+			doc.Relations[objectType][relation] = []string{}
+			for _, rewrite := range rewrites {
+				doc.Relations[objectType][relation] = append(doc.Relations[objectType][relation], rewrite.String())
+			}
+		}
+	}
+	entities := collectEntities(ac.Entities)
+	roots := make([]*Resource, 0, len(ac.Resources))
+	for _, resource := range ac.Resources {
+		roots = append(roots, rootResource(resource))
+	}
+	ac.mu.RUnlock()
+
+	for _, tuple := range ac.Tuples() {
+		doc.Tuples = append(doc.Tuples, tuple.String())
+	}
+
+	roles := ac.Roles()
+	for _, entity := range entities {
+		for _, binding := range entity.GetRoles() {
+			if !slices.Contains(roles, binding.Role) {
+				roles = append(roles, binding.Role)
+			}
+		}
+	}
+	for _, role := range roles {
+		for resource := range role.GetGrants() {
+			roots = append(roots, rootResource(resource))
+		}
+	}
+
+	resources := collectResources(roots)
+	for {
+		grown := false
+		for _, entity := range entities {
+			for _, rule := range entity.rules() {
+				if rule.Resource != nil && !slices.Contains(resources, rule.Resource) {
+					resources = collectResources(append(resources, rootResource(rule.Resource)))
+					grown = true
+				}
+			}
+			for _, binding := range entity.GetRoles() {
+				if binding.Scope != nil && !slices.Contains(resources, binding.Scope) {
+					resources = collectResources(append(resources, rootResource(binding.Scope)))
+					grown = true
+				}
+			}
+		}
+		for _, resource := range resources {
+			for _, owner := range resource.GetOwners() {
+				if !slices.Contains(entities, owner) {
+					entities = collectEntities(append(entities, owner))
+					grown = true
+				}
+			}
+		}
+		if !grown {
+			break
+		}
+	}
+
+	entityIDs := make(map[registryKey]*Entity)
+	for _, entity := range entities {
+		key := registryKey{tenant: entity.Tenant, id: entity.ID}
+		if existing, ok := entityIDs[key]; ok && existing != entity {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateEntity, key)
+		}
+		entityIDs[key] = entity
+	}
+
+	rootIDs := make(map[registryKey]bool)
+	visited := make(map[*Resource]bool)
+	for _, resource := range resources {
+		if resource.GetParent() != nil {
+			continue
+		}
+		key := registryKey{tenant: resource.Tenant, id: resource.ID}
+		if rootIDs[key] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateResource, key)
+		}
+		rootIDs[key] = true
+		doc.Resources = append(doc.Resources, resourceToDocument(resource, visited))
+	}
+	sort.Slice(doc.Resources, func(i, j int) bool {
+		a, b := doc.Resources[i], doc.Resources[j]
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.ID < b.ID
+	})
+
+	roleIDs := make(map[string]bool)
+	for _, role := range roles {
+		if roleIDs[role.ID] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateRole, role.ID)
+		}
+		roleIDs[role.ID] = true
+		doc.Roles = append(doc.Roles, roleToDocument(role))
+	}
+	sort.Slice(doc.Roles, func(i, j int) bool { return doc.Roles[i].ID < doc.Roles[j].ID })
+
+	for _, entity := range entities {
+		entityDoc, err := entityToDocument(entity)
+		if err != nil {
+			return nil, err
+		}
+		doc.Entities = append(doc.Entities, entityDoc)
+	}
+	sort.Slice(doc.Entities, func(i, j int) bool {
+		a, b := doc.Entities[i], doc.Entities[j]
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.ID < b.ID
+	})
+
+	if declared := doc.declaredPermissions(); declared != nil {
+		// Declare permissions used without declaration, so the document loads.
+		for _, permission := range doc.usedPermissions() {
+			if !slices.Contains(declared, permission) {
+				declared = append(declared, permission)
+				doc.Permissions = append(doc.Permissions, permissionDocument{Name: permission})
+			}
+		}
+	}
+	sort.Slice(doc.Permissions, func(i, j int) bool { return doc.Permissions[i].Name < doc.Permissions[j].Name })
+
+	return doc, nil
+}
+
+// resourceToDocument encodes the resource and its subresources, skipping
+// visited ones, so subresources modified into a cycle are encoded once.
+func resourceToDocument(resource *Resource, visited map[*Resource]bool) resourceDocument {
+	visited[resource] = true
+	doc := resourceDocument{ID: resource.ID, Tenant: resource.Tenant, Type: resource.Type, Attributes: resource.Attributes.Map()}
+	for _, owner := range resource.GetOwners() {
+		doc.Owners = append(doc.Owners, owner.ID)
+	}
+	for _, sub := range resource.GetSubs() {
+		if sub != nil && !visited[sub] {
+			doc.Resources = append(doc.Resources, resourceToDocument(sub, visited))
+		}
+	}
+	sort.Slice(doc.Resources, func(i, j int) bool { return doc.Resources[i].ID < doc.Resources[j].ID })
+	return doc
+}
+
+func roleToDocument(role *Role) roleDocument {
+	doc := roleDocument{ID: role.ID, Permissions: role.GetPermissions()}
+	for resource, permissions := range role.GetGrants() {
+		tenant := resource.GetTenant()
+		if tenant == "" {
+			doc.Grants = addGrantPaths(doc.Grants, resource.Path(), permissions)
+			continue
+		}
+		if doc.TenantGrants == nil {
+			doc.TenantGrants = make(map[string]map[Permission][]string)
+		}
+		doc.TenantGrants[tenant] = addGrantPaths(doc.TenantGrants[tenant], resource.Path(), permissions)
+	}
+	for _, paths := range doc.Grants {
+		sort.Strings(paths)
+	}
+	for _, grants := range doc.TenantGrants {
+		for _, paths := range grants {
+			sort.Strings(paths)
+		}
+	}
+	return doc
+}
+
+// addGrantPaths adds the path to grants of the permissions.
+func addGrantPaths(grants map[Permission][]string, path string, permissions []Permission) map[Permission][]string {
+	if grants == nil {
+		grants = make(map[Permission][]string)
+	}
+	for _, permission := range permissions {
+		grants[permission] = append(grants[permission], path)
+	}
+	return grants
+}
+
+func entityToDocument(entity *Entity) (entityDocument, error) {
+	doc := entityDocument{ID: entity.ID, Tenant: entity.Tenant, Attributes: entity.Attributes.Map()}
+	for _, parent := range entity.GetParents() {
+		doc.Parents = append(doc.Parents, parent.ID)
+	}
+
+	for _, rule := range entity.rules() {
+		path := rule.Pattern
+		if rule.Resource != nil {
+			path = rule.Resource.Path()
+		}
+
+		if len(rule.Conditions) > 0 {
+			ruleDoc, err := ruleToDocument(rule, path)
+			if err != nil {
+				return doc, err
+			}
+			doc.Rules = append(doc.Rules, ruleDoc)
+			continue
+		}
+
+		rules := &doc.Deny
+		if rule.Allow {
+			rules = &doc.Allow
+		}
+		if *rules == nil {
+			*rules = make(map[Permission][]string)
+		}
+		(*rules)[rule.Permission] = append((*rules)[rule.Permission], path)
+	}
+	for _, rules := range []map[Permission][]string{doc.Allow, doc.Deny} {
+		for _, paths := range rules {
+			sort.Strings(paths)
+		}
+	}
+	for _, binding := range entity.GetRoles() {
+		bindingDoc := bindingDocument{Role: binding.Role.ID}
+		if binding.Scope != nil {
+			bindingDoc.Scope = binding.Scope.Path()
+		}
+		doc.Roles = append(doc.Roles, bindingDoc)
+	}
+
+	sort.Slice(doc.Rules, func(i, j int) bool {
+		a, b := doc.Rules[i], doc.Rules[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Allow+a.Deny != b.Allow+b.Deny {
+			return a.Allow+a.Deny < b.Allow+b.Deny
+		}
+		return a.Allow < b.Allow
+	})
+	return doc, nil
+}
+
+// ruleToDocument converts a conditional rule. Expressions are joined by &&,
+// at most one Validity and Schedule is supported and conditions implemented
+// in Go cannot be serialized.
+func ruleToDocument(rule Rule, path string) (ruleDocument, error) {
+	doc := ruleDocument{Deny: rule.Permission, Resource: path}
+	if rule.Allow {
+		doc = ruleDocument{Allow: rule.Permission, Resource: path}
+	}
+
+	var sources []string
+	unserializable := fmt.Errorf("%w: %s", ErrUnserializableCondition, rule)
+	for _, condition := range rule.Conditions {
+		switch c := condition.(type) {
+		case *Expression:
+			sources = append(sources, c.String())
+		case Validity:
+			if doc.NotBefore != nil || doc.NotAfter != nil {
+				return doc, unserializable
+			}
+			if !c.NotBefore.IsZero() {
+				doc.NotBefore = &c.NotBefore
+			}
+			if !c.NotAfter.IsZero() {
+				doc.NotAfter = &c.NotAfter
+			}
+		case Schedule:
+			if doc.Schedule != nil {
+				return doc, unserializable
+			}
+			doc.Schedule = scheduleToDocument(c)
+		default:
+			return doc, unserializable
+		}
+	}
+
+	switch len(sources) {
+	case 0:
+	case 1:
+		doc.If = sources[0]
+	default:
+		doc.If = "(" + strings.Join(sources, ") && (") + ")"
+	}
+	return doc, nil
+}
+
+// conditions compiles the expression, validity and schedule of the rule.
+func (doc *ruleDocument) conditions() ([]Condition, error) {
+	var conditions []Condition
+	if doc.If != "" {
+		expression, err := Compile(doc.If)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, expression)
+	}
+	if doc.NotBefore != nil || doc.NotAfter != nil {
+		var validity Validity
+		if doc.NotBefore != nil {
+			validity.NotBefore = *doc.NotBefore
+		}
+		if doc.NotAfter != nil {
+			validity.NotAfter = *doc.NotAfter
+		}
+		conditions = append(conditions, validity)
+	}
+	if doc.Schedule != nil {
+		schedule, err := doc.Schedule.schedule()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, schedule)
+	}
+	return conditions, nil
+}
+
+// build creates the graph described by the document in an empty AccessControl.
+func (doc *document) build(ac *AccessControl) error {
+	ac.SetStrategy(doc.Strategy)
+
+	declared := doc.declaredPermissions()
+	for _, implications := range []map[Permission][]Permission{doc.Implies, doc.Sets} {
+		for permission, implied := range implications {
+			for _, p := range append([]Permission{permission}, implied...) {
+				if len(declared) > 0 && !slices.Contains(builtinPermissions, p) && !slices.Contains(declared, p) {
+					return fmt.Errorf("implication of %s: %w: %s", permission, ErrUnknownPermission, p)
+				}
+			}
+		}
+	}
+	for permission, implied := range doc.Implies {
+		ac.Imply(permission, implied...)
+	}
+	for name, members := range doc.Sets {
+		ac.DefineSet(name, members...)
+	}
+	for _, permission := range doc.Permissions {
+		ac.DeclarePermission(permission.Name, permission.Description, permission.Category)
+	}
+	ac.SetStrictPermissions(doc.Strict)
+	for _, resourceType := range doc.Types {
+		for _, permission := range resourceType.Permissions {
+			if len(declared) > 0 && !slices.Contains(declared, permission) {
+				return fmt.Errorf("resource type %s: %w: %s", resourceType.Name, ErrUnknownPermission, permission)
+			}
+		}
+		ac.DefineResourceType(resourceType.Name, resourceType.Permissions, resourceType.Relations)
+	}
+
+	for _, resource := range doc.Resources {
+		root, err := resource.build()
+		if err != nil {
+			return err
+		}
+		if err := ac.RegisterResource(root); err != nil {
+			return atLine(resource.line, err)
+		}
+	}
+
+	for _, role := range doc.Roles {
+		if err := role.build(ac, declared); err != nil {
+			return err
+		}
+	}
+
+	for _, entity := range doc.Entities {
+		created := NewEntity(entity.ID)
+		created.Tenant = entity.Tenant
+		for key, value := range entity.Attributes {
+			created.Attributes.Set(key, value)
+		}
+		if err := ac.RegisterEntity(created); err != nil {
+			return atLine(entity.line, err)
+		}
+		if err := entity.validatePermissions(declared); err != nil {
+			return err
+		}
+	}
+
+	for _, resource := range doc.Resources {
+		if err := resource.buildOwners(ac, resource.Tenant, ""); err != nil {
+			return err
+		}
+	}
+
+	for _, entity := range doc.Entities {
+		if err := entity.build(ac); err != nil {
+			return err
+		}
+	}
+
+	for objectType, relations := range doc.Relations {
+		for relation, values := range relations {
+			rewrites := make([]Rewrite, 0, len(values))
+			for _, value := range values {
+				rewrite, err := ParseRewrite(value)
+				if err != nil {
+					return fmt.Errorf("relation %s of %s: %w", relation, objectType, err)
+				}
+				rewrites = append(rewrites, rewrite)
+			}
+			ac.DefineRelation(objectType, relation, rewrites...)
+		}
+	}
+	for _, value := range doc.Tuples {
+		tuple, err := ParseTuple(value)
+		if err != nil {
+			return err
+		}
+		if err := ac.WriteTuple(tuple); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// declaredPermissions returns the built-in and declared permissions together
+// with the names of permission sets, or nil when the document is not strict
+// and declares no permissions.
+func (doc *document) declaredPermissions() []Permission {
+	if len(doc.Permissions) == 0 && !doc.Strict {
+		return nil
+	}
+	declared := slices.Clone(builtinPermissions)
+	for _, permission := range doc.Permissions {
+		declared = append(declared, permission.Name)
+	}
+	for name := range doc.Sets {
+		declared = append(declared, name)
+	}
+	return declared
+}
+
+// usedPermissions returns the sorted permissions of rules, roles and
+// implications of the document.
+func (doc *document) usedPermissions() []Permission {
+	var used []Permission
+	add := func(permissions ...Permission) {
+		for _, permission := range permissions {
+			if permission != "" && !slices.Contains(used, permission) {
+				used = append(used, permission)
+			}
+		}
+	}
+	for permission, implied := range doc.Implies {
+		add(permission)
+		add(implied...)
+	}
+	for _, members := range doc.Sets {
+		add(members...)
+	}
+	for _, resourceType := range doc.Types {
+		add(resourceType.Permissions...)
+	}
+	for _, role := range doc.Roles {
+		add(role.Permissions...)
+		for permission := range role.Grants {
+			add(permission)
+		}
+		for _, grants := range role.TenantGrants {
+			for permission := range grants {
+				add(permission)
+			}
+		}
+	}
+	for _, entity := range doc.Entities {
+		for _, rules := range []map[Permission][]string{entity.Allow, entity.Deny} {
+			for permission := range rules {
+				add(permission)
+			}
+		}
+		for _, rule := range entity.Rules {
+			add(rule.Allow, rule.Deny)
+		}
+	}
+	sort.Slice(used, func(i, j int) bool { return used[i] < used[j] })
+	return used
+}
+
+func (doc *resourceDocument) build() (*Resource, error) {
+	resource := NewResource(doc.ID)
+	resource.Tenant = doc.Tenant
+	resource.Type = doc.Type
+	for key, value := range doc.Attributes {
+		resource.Attributes.Set(key, value)
+	}
+	for _, sub := range doc.Resources {
+		if resource.GetSub(sub.ID) != nil {
+			return nil, atLine(sub.line, fmt.Errorf("%w: %s/%s", ErrDuplicateResource, doc.ID, sub.ID))
+		}
+		child, err := sub.build()
+		if err != nil {
+			return nil, err
+		}
+		if err := resource.AttachSubs(child); err != nil {
+			return nil, atLine(sub.line, err)
+		}
+	}
+	return resource, nil
+}
+
+func (doc *resourceDocument) buildOwners(ac *AccessControl, tenant, parentPath string) error {
+	path := strings.TrimPrefix(parentPath+"/"+doc.ID, "/")
+	resource, err := ac.GetResourceInTenant(tenant, path)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range doc.Owners {
+		owner, err := ac.lookupEntity(tenant, id)
+		if err != nil {
+			return atLine(doc.line, fmt.Errorf("owner of %s: %w", path, err))
+		}
+		resource.AddOwners(owner)
+	}
+
+	for _, sub := range doc.Resources {
+		if err := sub.buildOwners(ac, tenant, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (doc *entityDocument) build(ac *AccessControl) error {
+	entity, err := ac.GetEntityInTenant(doc.Tenant, doc.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range doc.Parents {
+		parent, err := ac.lookupEntity(doc.Tenant, id)
+		if err != nil {
+			return atLine(doc.line, fmt.Errorf("parent of %s: %w", doc.ID, err))
+		}
+		if err := entity.AddParents(parent); err != nil {
+			return atLine(doc.line, err)
+		}
+	}
+
+	for _, rules := range []struct {
+		paths map[Permission][]string
+		allow bool
+	}{{doc.Allow, true}, {doc.Deny, false}} {
+		for permission, paths := range rules.paths {
+			for _, path := range paths {
+				if err := addDocumentRule(ac, entity, permission, path, rules.allow); err != nil {
+					return atLine(doc.line, fmt.Errorf("rule of %s: %w", doc.ID, err))
+				}
+			}
+		}
+	}
+
+	for _, binding := range doc.Roles {
+		role, err := ac.GetRole(binding.Role)
+		if err != nil {
+			return atLine(doc.line, fmt.Errorf("role of %s: %w", doc.ID, err))
+		}
+		var scope *Resource
+		if binding.Scope != "" {
+			if scope, err = ac.lookupResource(doc.Tenant, binding.Scope); err != nil {
+				return atLine(doc.line, fmt.Errorf("role of %s: %w", doc.ID, err))
+			}
+			if err := ac.ValidateGrant(entity, scope, ""); err != nil {
+				return atLine(doc.line, fmt.Errorf("role of %s: %w", doc.ID, err))
+			}
+		}
+		entity.AssignRole(role, scope)
+	}
+
+	for _, rule := range doc.Rules {
+		if (rule.Allow == "") == (rule.Deny == "") {
+			return atLine(rule.line, fmt.Errorf("rule of %s: exactly one of allow and deny must be set", doc.ID))
+		}
+		permission, allow := rule.Allow, true
+		if rule.Deny != "" {
+			permission, allow = rule.Deny, false
+		}
+
+		conditions, err := rule.conditions()
+		if err != nil {
+			return atLine(rule.line, fmt.Errorf("rule of %s: %w", doc.ID, err))
+		}
+		if err := addDocumentRule(ac, entity, permission, rule.Resource, allow, conditions...); err != nil {
+			return atLine(rule.line, fmt.Errorf("rule of %s: %w", doc.ID, err))
+		}
+	}
+
+	return nil
+}
+
+func (doc *roleDocument) build(ac *AccessControl, declared []Permission) error {
+	role := NewRole(doc.ID, doc.Permissions...)
+	permissions := slices.Clone(doc.Permissions)
+	grants := map[string]map[Permission][]string{"": doc.Grants}
+	for tenant, tenantGrants := range doc.TenantGrants {
+		if tenant == "" {
+			return atLine(doc.line, fmt.Errorf("grant of role %s: empty tenant", doc.ID))
+		}
+		grants[tenant] = tenantGrants
+	}
+	for tenant, tenantGrants := range grants {
+		for permission, paths := range tenantGrants {
+			permissions = append(permissions, permission)
+			for _, path := range paths {
+				resource, err := ac.GetResourceInTenant(tenant, path)
+				if err != nil {
+					return atLine(doc.line, fmt.Errorf("grant of role %s: %w", doc.ID, err))
+				}
+				role.AllowOn(resource, permission)
+			}
+		}
+	}
+
+	if len(declared) > 0 {
+		for _, permission := range permissions {
+			if !slices.Contains(builtinPermissions, permission) && !slices.Contains(declared, permission) {
+				return atLine(doc.line, fmt.Errorf("role %s: %w: %s", doc.ID, ErrUnknownPermission, permission))
+			}
+		}
+	}
+
+	if err := ac.RegisterRole(role); err != nil {
+		return atLine(doc.line, err)
+	}
+	return nil
+}
+
+// addDocumentRule sets a rule for a resource path, or a pattern when the path
+// contains wildcards. Paths are resolved in the tenant of the entity first.
+func addDocumentRule(ac *AccessControl, entity *Entity, permission Permission, path string, allow bool, conditions ...Condition) error {
+	if IsPattern(path) {
+		if err := ValidatePattern(path); err != nil {
+			return err
+		}
+		entity.AddPermPatternIf(permission, path, allow, conditions...)
+		return nil
+	}
+
+	resource, err := ac.lookupResource(entity.Tenant, path)
+	if err != nil {
+		return err
+	}
+	if err := ac.ValidateGrant(entity, resource, permission); err != nil {
+		return err
+	}
+	entity.AddPermIf(permission, resource, allow, conditions...)
+	return nil
+}
+
+// validatePermissions checks that rules use built-in or declared permissions.
+// Nothing is checked when no permissions are declared.
+func (doc *entityDocument) validatePermissions(declared []Permission) error {
+	if len(declared) == 0 {
+		return nil
+	}
+
+	for _, rules := range []map[Permission][]string{doc.Allow, doc.Deny} {
+		for permission := range rules {
+			if !slices.Contains(builtinPermissions, permission) && !slices.Contains(declared, permission) {
+				return atLine(doc.line, fmt.Errorf("rule of %s: %w: %s", doc.ID, ErrUnknownPermission, permission))
+			}
+		}
+	}
+	for _, rule := range doc.Rules {
+		for _, permission := range []Permission{rule.Allow, rule.Deny} {
+			if permission != "" && !slices.Contains(builtinPermissions, permission) && !slices.Contains(declared, permission) {
+				return atLine(rule.line, fmt.Errorf("rule of %s: %w: %s", doc.ID, ErrUnknownPermission, permission))
+			}
+		}
+	}
+	return nil
+}
+
+// atLine attaches the source line of a document element to an error, elements
+// not decoded from YAML have no line.
+func atLine(line int, err error) error {
+	if line == 0 {
+		return err
+	}
+	return &PolicyError{Line: line, Err: err}
+}
+
+// rootResource returns the topmost ancestor of the resource.
+func rootResource(resource *Resource) *Resource {
+	visited := map[*Resource]bool{resource: true}
+	for parent := resource.GetParent(); parent != nil && !visited[parent]; parent = parent.GetParent() {
+		visited[parent] = true
+		resource = parent
+	}
+	return resource
+}
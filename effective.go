@@ -0,0 +1,153 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Grant is an allowed permission of an entity for a resource with its source.
+type Grant struct {
+	Permission Permission
+	// Decision is the allowing decision, with the rule or ownership and the
+	// entity and resource it came from.
+	Decision Decision
+	// Inherited is true when the rule or ownership comes from an ancestor of
+	// the entity, Decision.Entity.
+	Inherited bool
+	// FromAncestor is true when the rule or ownership is attached to an
+	// ancestor of the resource, Decision.Resource.
+	FromAncestor bool
+}
+
+func (g Grant) String() string {
+	source := "rule"
+	switch {
+	case g.Decision.Owner:
+		source = "ownership"
+	case g.Decision.Rule != nil && g.Decision.Rule.Role != nil:
+		source = "role " + g.Decision.Rule.Role.ID
+	}
+	if g.Inherited {
+		source += " inherited from " + g.Decision.Entity.ID
+	}
+	if g.FromAncestor {
+		source += " on " + g.Decision.Resource.Path()
+	}
+	return fmt.Sprintf("%s (%s)", g.Permission, source)
+}
+
+// ResourcePermissions lists the allowed permissions of an entity for a resource.
+type ResourcePermissions struct {
+	Resource *Resource
+	Grants   []Grant
+}
+
+// Permissions returns the allowed permissions.
+func (r ResourcePermissions) Permissions() []Permission {
+	permissions := make([]Permission, 0, len(r.Grants))
+	for _, grant := range r.Grants {
+		permissions = append(permissions, grant.Permission)
+	}
+	return permissions
+}
+
+// EffectivePermissions returns, for every resource the entity has a permission
+// for, the allowed permissions and their source, sorted by tenant and path.
+// Checked permissions are those applicable to the resource (see
+// PermissionsFor), used in implications and used by rules and roles of the
+// entity and its ancestors.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	group := ac.CreateEntity("group")
+//	user := group.CreateChild("user")
+//	docs := ac.CreateResource("docs")
+//	readme := docs.CreateSub("readme")
+//	ac.Allow(group, docs, permission.Read)
+//	readme.AddOwners(user)
+//	for _, access := range ac.EffectivePermissions(user) {
+//		fmt.Println(access.Resource.Path(), access.Grants)
+//	}
+//	// docs [READ (rule inherited from group)]
+//	// docs/readme [CREATE (ownership) READ (ownership) UPDATE (ownership) DELETE (ownership)]
+func (ac *AccessControl) EffectivePermissions(entity *Entity) []ResourcePermissions {
+	resources := collectResources(ac.rootResources())
+	used := ac.usedPermissions(entity)
+
+	var effective []ResourcePermissions
+	seeds := make(map[Permission]map[*Resource]bool)
+	for _, resource := range resources {
+		access := ResourcePermissions{Resource: resource}
+		for _, permission := range ac.effectiveCandidates(resource, used) {
+			if seeds[permission] == nil {
+				seeds[permission] = ac.allowSeeds(entity, permission, resources)
+			}
+			if !withinSeeds(resource, seeds[permission]) {
+				continue
+			}
+			decision := ac.Explain(entity, resource, permission)
+			if !decision.Allowed {
+				continue
+			}
+			access.Grants = append(access.Grants, Grant{
+				Permission:   permission,
+				Decision:     decision,
+				Inherited:    decision.Entity != entity,
+				FromAncestor: decision.Resource != resource,
+			})
+		}
+		if len(access.Grants) > 0 {
+			effective = append(effective, access)
+		}
+	}
+
+	sort.Slice(effective, func(i, j int) bool {
+		a, b := effective[i].Resource, effective[j].Resource
+		if a.GetTenant() != b.GetTenant() {
+			return a.GetTenant() < b.GetTenant()
+		}
+		return a.Path() < b.Path()
+	})
+	return effective
+}
+
+// usedPermissions returns permissions of implications and of rules and roles
+// of the entity and its ancestors, other than All.
+func (ac *AccessControl) usedPermissions(entity *Entity) []Permission {
+	var used []Permission
+	add := func(permissions ...Permission) {
+		for _, permission := range permissions {
+			if permission != All && !slices.Contains(used, permission) {
+				used = append(used, permission)
+			}
+		}
+	}
+
+	add(ac.Expand(All)...)
+	for _, e := range newEvaluation(entity, nil, All, nil).entities {
+		for _, rule := range e.entity.rules() {
+			add(rule.Permission)
+		}
+		for _, binding := range e.entity.GetRoles() {
+			add(binding.Role.GetPermissions()...)
+			for _, permissions := range binding.Role.GetGrants() {
+				add(permissions...)
+			}
+		}
+	}
+	return used
+}
+
+// effectiveCandidates returns the permissions applicable to the resource
+// followed by the used permissions, each once.
+func (ac *AccessControl) effectiveCandidates(resource *Resource, used []Permission) []Permission {
+	candidates := ac.PermissionsFor(resource)
+	for _, permission := range used {
+		if !slices.Contains(candidates, permission) {
+			candidates = append(candidates, permission)
+		}
+	}
+	return candidates
+}
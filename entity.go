@@ -1,13 +1,32 @@
 package permission
 
-import "github.com/gouef/utils"
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
 
 // Entity represents a user, group, role (or what you want) with specific permissions.
+//
+// Methods of Entity are safe for concurrent use. Fields should not be modified
+// directly once the entity is shared between goroutines.
 type Entity struct {
-	ID         string
+	ID string
+	// Tenant scopes the entity to a tenant, empty for a global entity.
+	Tenant     string
 	Parents    []*Entity
 	Children   []*Entity
 	Permission map[Permission]map[*Resource]bool
+	// Patterns holds rules for resource patterns like "web/comments/*", see IsPattern.
+	Patterns map[Permission]map[string]bool
+	// Attributes holds metadata of the entity, like a department.
+	Attributes Attributes
+
+	conditions map[ruleKey][]Condition
+	roles      []RoleBinding
+	// version counts changes, see decisionCache.
+	version atomic.Uint64
+	mu      sync.RWMutex
 }
 
 // NewEntity creates a new entity with default permission sets.
@@ -29,10 +48,11 @@ func NewEntity(id string) *Entity {
 		Parents:    make([]*Entity, 0),
 		Children:   make([]*Entity, 0),
 		Permission: perms,
+		Patterns:   make(map[Permission]map[string]bool),
 	}
 }
 
-// CreateChild creates a child entity and assigns it as a descendant.
+// CreateChild creates a child entity in the same tenant and assigns it as a descendant.
 //
 // Example:
 //
@@ -41,41 +61,74 @@ func NewEntity(id string) *Entity {
 //	fmt.Println(child.ID) // Output: user
 func (e *Entity) CreateChild(id string) *Entity {
 	child := NewEntity(id)
+	child.Tenant = e.Tenant
 	e.AddChildren(child)
 
 	return child
 }
 
 // AddChildren associates child entities with the current entity.
+// It stops at the first child that would create a cycle and returns a *CycleError.
+// Children of a tenant entity must be in the same tenant, otherwise an error
+// wrapping ErrCrossTenant is returned.
 //
 // Example:
 //
 //	admin := permission.NewEntity("admin")
 //	user := permission.NewEntity("user")
 //	admin.AddChildren(user)
-func (e *Entity) AddChildren(children ...*Entity) {
-	e.Children = append(e.Children, children...)
+func (e *Entity) AddChildren(children ...*Entity) error {
 	for _, child := range children {
-		if !child.parentExists(e) {
-			child.AddParents(e)
+		if err := linkEntities(e, child); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 // AddParents associates parent entities with the current entity.
+// It stops at the first parent that would create a cycle and returns a *CycleError.
+// A parent must be global or in the tenant of the entity, otherwise an error
+// wrapping ErrCrossTenant is returned.
 //
 // Example:
 //
 //	admin := permission.NewEntity("admin")
 //	user := permission.NewEntity("user")
 //	user.AddParents(admin)
-func (e *Entity) AddParents(parents ...*Entity) {
-	e.Parents = append(e.Parents, parents...)
+func (e *Entity) AddParents(parents ...*Entity) error {
 	for _, parent := range parents {
-		if !parent.childExists(e) {
-			parent.AddChildren(e)
+		if err := linkEntities(parent, e); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// GetParents returns a snapshot of the entity parents.
+//
+// Example:
+//
+//	admin := permission.NewEntity("admin")
+//	user := admin.CreateChild("user")
+//	fmt.Println(user.GetParents()[0].ID) // Output: admin
+func (e *Entity) GetParents() []*Entity {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]*Entity(nil), e.Parents...)
+}
+
+// GetChildren returns a snapshot of the entity children.
+//
+// Example:
+//
+//	admin := permission.NewEntity("admin")
+//	admin.CreateChild("user")
+//	fmt.Println(admin.GetChildren()[0].ID) // Output: user
+func (e *Entity) GetChildren() []*Entity {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]*Entity(nil), e.Children...)
 }
 
 // Allow grants specified permissions for a resource to the entity.
@@ -104,12 +157,176 @@ func (e *Entity) Deny(resource *Resource, permissions ...Permission) {
 	}
 }
 
+// Revoke removes the rules of specified permissions for a resource, returning
+// the entity to the "no rule" state, so permissions are inherited again.
+//
+// Example:
+//
+//	user := permission.NewEntity("user")
+//	res := permission.NewResource("file")
+//	user.Deny(res, permission.Read)
+//	user.Revoke(res, permission.Read)
+func (e *Entity) Revoke(resource *Resource, permissions ...Permission) {
+	for _, permission := range permissions {
+		e.RemovePerm(permission, resource)
+	}
+}
+
+// RevokeResource removes all rules of the entity for a resource.
+//
+// Example:
+//
+//	user := permission.NewEntity("user")
+//	res := permission.NewResource("file")
+//	user.Allow(res, permission.Read, permission.Update)
+//	user.RevokeResource(res)
+func (e *Entity) RevokeResource(resource *Resource) {
+	defer e.touch()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, perms := range e.Permission {
+		delete(perms, resource)
+	}
+	for key := range e.conditions {
+		if key.resource == resource {
+			delete(e.conditions, key)
+		}
+	}
+}
+
+// RemovePerm removes the rule of a specific permission for a resource.
+func (e *Entity) RemovePerm(permission Permission, resource *Resource) {
+	defer e.touch()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.Permission[permission], resource)
+	delete(e.conditions, ruleKey{permission: permission, resource: resource})
+}
+
+// RemoveParents disconnects parent entities from the current entity in both directions.
+//
+// Example:
+//
+//	admin := permission.NewEntity("admin")
+//	user := admin.CreateChild("user")
+//	user.RemoveParents(admin)
+func (e *Entity) RemoveParents(parents ...*Entity) {
+	for _, parent := range parents {
+		unlinkEntities(parent, e)
+	}
+}
+
+// RemoveChildren disconnects child entities from the current entity in both directions.
+//
+// Example:
+//
+//	admin := permission.NewEntity("admin")
+//	user := admin.CreateChild("user")
+//	admin.RemoveChildren(user)
+func (e *Entity) RemoveChildren(children ...*Entity) {
+	for _, child := range children {
+		unlinkEntities(e, child)
+	}
+}
+
 // AddPerm sets or removes a specific permission for a resource.
 func (e *Entity) AddPerm(permission Permission, resource *Resource, enabled bool) {
+	e.AddPermIf(permission, resource, enabled)
+}
+
+// AddPermIf sets a specific permission for a resource, applying only when all
+// conditions are met. Without conditions it is the same as AddPerm.
+//
+// Example:
+//
+//	editors := permission.NewEntity("editors")
+//	articles := permission.NewResource("articles")
+//	editors.AddPermIf(permission.Update, articles, true, permission.RequestAttrEquals("status", "draft"))
+func (e *Entity) AddPermIf(permission Permission, resource *Resource, enabled bool, conditions ...Condition) {
+	defer e.touch()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.Permission == nil {
+		e.Permission = make(map[Permission]map[*Resource]bool)
+	}
 	if _, ok := e.Permission[permission]; !ok {
 		e.Permission[permission] = make(map[*Resource]bool)
 	}
 	e.Permission[permission][resource] = enabled
+	e.setConditions(ruleKey{permission: permission, resource: resource}, conditions)
+}
+
+// AllowPattern grants specified permissions for every resource matching the pattern.
+//
+// Example:
+//
+//	user := permission.NewEntity("user")
+//	user.AllowPattern("web/comments/*", permission.Read)
+func (e *Entity) AllowPattern(pattern string, permissions ...Permission) {
+	for _, permission := range permissions {
+		e.AddPermPattern(permission, pattern, true)
+	}
+}
+
+// DenyPattern denies specified permissions for every resource matching the pattern.
+//
+// Example:
+//
+//	user := permission.NewEntity("user")
+//	user.DenyPattern("reports/2026-*", permission.Delete)
+func (e *Entity) DenyPattern(pattern string, permissions ...Permission) {
+	for _, permission := range permissions {
+		e.AddPermPattern(permission, pattern, false)
+	}
+}
+
+// RevokePattern removes the pattern rules of specified permissions.
+//
+// Example:
+//
+//	user := permission.NewEntity("user")
+//	user.AllowPattern("web/**", permission.Read)
+//	user.RevokePattern("web/**", permission.Read)
+func (e *Entity) RevokePattern(pattern string, permissions ...Permission) {
+	defer e.touch()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, permission := range permissions {
+		delete(e.Patterns[permission], pattern)
+		delete(e.conditions, ruleKey{permission: permission, pattern: pattern})
+	}
+}
+
+// AddPermPattern sets a specific permission for every resource matching the pattern.
+// Rules for a concrete resource are more specific than pattern rules.
+func (e *Entity) AddPermPattern(permission Permission, pattern string, enabled bool) {
+	e.AddPermPatternIf(permission, pattern, enabled)
+}
+
+// AddPermPatternIf sets a specific permission for every resource matching the
+// pattern, applying only when all conditions are met.
+//
+// Example:
+//
+//	users := permission.NewEntity("users")
+//	users.AddPermPatternIf(permission.Read, "reports/*", true, permission.RequestAttrEquals("vpn", true))
+func (e *Entity) AddPermPatternIf(permission Permission, pattern string, enabled bool, conditions ...Condition) {
+	defer e.touch()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.Patterns == nil {
+		e.Patterns = make(map[Permission]map[string]bool)
+	}
+	if _, ok := e.Patterns[permission]; !ok {
+		e.Patterns[permission] = make(map[string]bool)
+	}
+	e.Patterns[permission][pattern] = enabled
+	e.setConditions(ruleKey{permission: permission, pattern: pattern}, conditions)
 }
 
 func (e *Entity) AddPermAll(resource *Resource, enabled bool) {
@@ -132,10 +349,82 @@ func (e *Entity) AddPermDelete(resource *Resource, enabled bool) {
 	e.AddPerm(Delete, resource, enabled)
 }
 
-func (e *Entity) parentExists(parent *Entity) bool {
-	return utils.InArray(parent, e.Parents)
+// setConditions replaces conditions of a rule, the caller holds the lock.
+func (e *Entity) setConditions(key ruleKey, conditions []Condition) {
+	if len(conditions) == 0 {
+		delete(e.conditions, key)
+		return
+	}
+	if e.conditions == nil {
+		e.conditions = make(map[ruleKey][]Condition)
+	}
+	e.conditions[key] = append([]Condition(nil), conditions...)
+}
+
+// rules returns a snapshot of all rules set directly on the entity, pattern
+// rules have no Resource.
+func (e *Entity) rules() []Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var rules []Rule
+	for permission, perms := range e.Permission {
+		for resource, enabled := range perms {
+			rules = append(rules, Rule{
+				Entity:     e,
+				Resource:   resource,
+				Permission: permission,
+				Allow:      enabled,
+				Conditions: e.conditions[ruleKey{permission: permission, resource: resource}],
+			})
+		}
+	}
+	for permission, patterns := range e.Patterns {
+		for pattern, enabled := range patterns {
+			rules = append(rules, Rule{
+				Entity:     e,
+				Pattern:    pattern,
+				Permission: permission,
+				Allow:      enabled,
+				Conditions: e.conditions[ruleKey{permission: permission, pattern: pattern}],
+			})
+		}
+	}
+	return rules
 }
 
-func (e *Entity) childExists(child *Entity) bool {
-	return utils.InArray(child, e.Children)
+// matchingRules returns rules of the entity for the permissions, set for the
+// resource and for patterns matching its path, each in the order of permissions.
+func (e *Entity) matchingRules(permissions []Permission, resource *Resource, path string) (concrete []Rule, patterns []Rule) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, permission := range permissions {
+		if enabled, ok := e.Permission[permission][resource]; ok {
+			concrete = append(concrete, Rule{
+				Entity:     e,
+				Resource:   resource,
+				Permission: permission,
+				Allow:      enabled,
+				Conditions: e.conditions[ruleKey{permission: permission, resource: resource}],
+			})
+		}
+	}
+
+	for _, permission := range permissions {
+		for pattern, enabled := range e.Patterns[permission] {
+			if MatchPattern(pattern, path) {
+				patterns = append(patterns, Rule{
+					Entity:     e,
+					Resource:   resource,
+					Pattern:    pattern,
+					Permission: permission,
+					Allow:      enabled,
+					Conditions: e.conditions[ruleKey{permission: permission, pattern: pattern}],
+				})
+			}
+		}
+	}
+	sort.SliceStable(patterns, func(i, j int) bool { return patterns[i].Pattern < patterns[j].Pattern })
+	return concrete, patterns
 }
@@ -0,0 +1,95 @@
+package permission
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDuplicateEntity is returned when an entity with an already registered ID is added.
+	ErrDuplicateEntity = errors.New("permission: duplicate entity")
+	// ErrDuplicateResource is returned when a resource with an already registered path is added.
+	ErrDuplicateResource = errors.New("permission: duplicate resource")
+	// ErrEntityNotFound is returned when no entity is registered under the requested ID.
+	ErrEntityNotFound = errors.New("permission: entity not found")
+	// ErrResourceNotFound is returned when no resource can be found under the requested path.
+	ErrResourceNotFound = errors.New("permission: resource not found")
+	// ErrDuplicateRole is returned when a role with an already registered ID is added.
+	ErrDuplicateRole = errors.New("permission: duplicate role")
+	// ErrRoleNotFound is returned when no role is registered under the requested ID.
+	ErrRoleNotFound = errors.New("permission: role not found")
+	// ErrUnknownPermission is returned when a policy or a strict AccessControl uses an undeclared permission.
+	ErrUnknownPermission = errors.New("permission: unknown permission")
+	// ErrBadPattern is returned for a malformed resource pattern.
+	ErrBadPattern = errors.New("permission: malformed resource pattern")
+	// ErrCondition is returned when a condition cannot be evaluated.
+	ErrCondition = errors.New("permission: condition failed")
+	// ErrUnserializableCondition is returned when encoding a rule with a condition implemented in Go.
+	ErrUnserializableCondition = errors.New("permission: condition cannot be serialized")
+	// ErrUnknownStrategy is returned when decoding an unknown strategy name.
+	ErrUnknownStrategy = errors.New("permission: unknown strategy")
+	// ErrResourceTypeNotFound is returned when no resource type is defined under the requested name.
+	ErrResourceTypeNotFound = errors.New("permission: resource type not found")
+	// ErrInapplicablePermission is returned when granting or checking a permission the resource type does not support.
+	ErrInapplicablePermission = errors.New("permission: permission not applicable to resource type")
+	// ErrBadTuple is returned for a malformed relation tuple, object, subject or rewrite.
+	ErrBadTuple = errors.New("permission: malformed relation tuple")
+	// ErrUnknownRelation is returned when writing a tuple of a relation the object type does not define.
+	ErrUnknownRelation = errors.New("permission: unknown relation")
+	// ErrExpression matches every *ExpressionError.
+	ErrExpression = errors.New("permission: invalid expression")
+	// ErrCrossTenant is returned when linking or granting across tenants.
+	ErrCrossTenant = errors.New("permission: cross-tenant access")
+	// ErrCycle matches every *CycleError.
+	ErrCycle = errors.New("permission: hierarchy cycle")
+	// ErrUnknownField is returned when a policy contains a key it does not define.
+	ErrUnknownField = errors.New("permission: unknown field")
+)
+
+// CycleError is returned when linking Child under Parent would create a cycle
+// in an entity or resource hierarchy.
+type CycleError struct {
+	Parent string
+	Child  string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("permission: linking %q under %q creates a cycle", e.Child, e.Parent)
+}
+
+// Is makes errors.Is(err, ErrCycle) true for every *CycleError.
+func (e *CycleError) Is(target error) bool {
+	return target == ErrCycle
+}
+
+// PolicyError is returned when a policy file is invalid, Line is the line of
+// the offending entity or resource.
+type PolicyError struct {
+	Line int
+	Err  error
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("permission: policy line %d: %v", e.Line, e.Err)
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+// ExpressionError is returned when an expression cannot be compiled, Position
+// is the offset of the offending character.
+type ExpressionError struct {
+	Expression string
+	Position   int
+	Message    string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("permission: expression %q at %d: %s", e.Expression, e.Position, e.Message)
+}
+
+// Is makes errors.Is(err, ErrExpression) true for every *ExpressionError.
+func (e *ExpressionError) Is(target error) bool {
+	return target == ErrExpression
+}
@@ -0,0 +1,655 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Expression is a compiled condition written in the expression language, like
+//
+//	subject.id in resource.owners || request.status == "draft" && time.hour < 18
+//
+// Expressions are sandboxed: they only read the checked request and cannot
+// call into Go code other than the built-in functions.
+//
+// Values are booleans, numbers, strings and lists. Operators by precedence are
+// || then && then comparisons == != < <= > >= and in, then + - then * / % and
+// unary ! -. The in operator tests list membership or a substring.
+//
+// Available attributes:
+//
+//	subject.id, subject.parents, subject.<attribute>[.<key>...]
+//	resource.id, resource.path, resource.parent, resource.owners, resource.<attribute>[.<key>...]
+//	permission
+//	request.<key>[.<key>...]
+//	time.year, time.month, time.day, time.hour, time.minute, time.weekday, time.unix
+//
+// Functions: lower(s), upper(s), len(s|list), startsWith(s, prefix),
+// endsWith(s, suffix), contains(s|list, value).
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// Compile parses and type-checks an expression, errors are *ExpressionError.
+//
+// Example:
+//
+//	workHours, err := permission.Compile(`time.hour >= 9 && time.hour < 17`)
+//	ac.AllowIf(users, reports, permission.Read, workHours)
+func Compile(source string) (*Expression, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{source: source, tokens: tokens}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := root.check()
+	if err != nil {
+		return nil, err
+	}
+	if typ != typeBool && typ != typeAny {
+		return nil, newExpressionError(source, root.pos(), "expression is %s, not bool", typ)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+//
+// Example:
+//
+//	draft := permission.MustCompile(`request.status == "draft"`)
+func MustCompile(source string) *Expression {
+	expression, err := Compile(source)
+	if err != nil {
+		panic(err)
+	}
+	return expression
+}
+
+// Evaluate implements Condition. Values of unexpected types are reported as
+// errors wrapping ErrCondition.
+func (e *Expression) Evaluate(ctx context.Context, request *Request) (bool, error) {
+	value, err := e.root.eval(&exprEnv{ctx: ctx, request: request})
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, newEvaluationError(e.source, e.root.pos(), "expression is %s, not bool", typeOf(value))
+	}
+	return result, nil
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// exprEnv is the environment an expression is evaluated in.
+type exprEnv struct {
+	ctx     context.Context
+	request *Request
+}
+
+// now returns the time of the request.
+func (env *exprEnv) now() time.Time {
+	return requestTime(env.request)
+}
+
+func newExpressionError(source string, pos int, format string, args ...any) error {
+	return &ExpressionError{Expression: source, Position: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func newEvaluationError(source string, pos int, format string, args ...any) error {
+	return fmt.Errorf("%w: expression %q at %d: %s", ErrCondition, source, pos, fmt.Sprintf(format, args...))
+}
+
+type literalNode struct {
+	value any
+	typ   exprType
+	at    int
+}
+
+func (n *literalNode) check() (exprType, error) { return n.typ, nil }
+
+func (n *literalNode) eval(env *exprEnv) (any, error) { return n.value, nil }
+
+func (n *literalNode) pos() int { return n.at }
+
+type listNode struct {
+	items []exprNode
+	at    int
+}
+
+func (n *listNode) check() (exprType, error) {
+	for _, item := range n.items {
+		if _, err := item.check(); err != nil {
+			return typeAny, err
+		}
+	}
+	return typeList, nil
+}
+
+func (n *listNode) eval(env *exprEnv) (any, error) {
+	list := make([]any, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+func (n *listNode) pos() int { return n.at }
+
+// attribute is a field of the subject, resource or time.
+type attribute struct {
+	typ   exprType
+	value func(env *exprEnv) any
+}
+
+var attributes = map[string]map[string]attribute{
+	"subject": {
+		"id": {typeString, func(env *exprEnv) any { return env.request.Subject.ID }},
+		"parents": {typeList, func(env *exprEnv) any {
+			ids := []any{}
+			for _, parent := range env.request.Subject.GetParents() {
+				ids = append(ids, parent.ID)
+			}
+			return ids
+		}},
+	},
+	"resource": {
+		"id":   {typeString, func(env *exprEnv) any { return env.request.Resource.ID }},
+		"path": {typeString, func(env *exprEnv) any { return env.request.Resource.Path() }},
+		"parent": {typeString, func(env *exprEnv) any {
+			if parent := env.request.Resource.GetParent(); parent != nil {
+				return parent.ID
+			}
+			return ""
+		}},
+		"owners": {typeList, func(env *exprEnv) any {
+			ids := []any{}
+			for _, owner := range env.request.Resource.GetOwners() {
+				ids = append(ids, owner.ID)
+			}
+			return ids
+		}},
+	},
+	"time": {
+		"year":    {typeNumber, func(env *exprEnv) any { return float64(env.now().Year()) }},
+		"month":   {typeNumber, func(env *exprEnv) any { return float64(env.now().Month()) }},
+		"day":     {typeNumber, func(env *exprEnv) any { return float64(env.now().Day()) }},
+		"hour":    {typeNumber, func(env *exprEnv) any { return float64(env.now().Hour()) }},
+		"minute":  {typeNumber, func(env *exprEnv) any { return float64(env.now().Minute()) }},
+		"weekday": {typeString, func(env *exprEnv) any { return env.now().Weekday().String() }},
+		"unix":    {typeNumber, func(env *exprEnv) any { return float64(env.now().Unix()) }},
+	},
+}
+
+type attributeNode struct {
+	root   string
+	path   []string
+	at     int
+	source string
+}
+
+func (n *attributeNode) name() string {
+	return strings.Join(append([]string{n.root}, n.path...), ".")
+}
+
+func (n *attributeNode) check() (exprType, error) {
+	switch n.root {
+	case "permission":
+		if len(n.path) > 0 {
+			return typeAny, newExpressionError(n.source, n.at, "unknown attribute %s", n.name())
+		}
+		return typeString, nil
+	case "request":
+		if len(n.path) == 0 {
+			return typeAny, newExpressionError(n.source, n.at, "missing request attribute name")
+		}
+		return typeAny, nil
+	}
+
+	fields, ok := attributes[n.root]
+	if !ok {
+		return typeAny, newExpressionError(n.source, n.at, "unknown name %s", n.root)
+	}
+	if len(n.path) == 0 {
+		return typeAny, newExpressionError(n.source, n.at, "missing %s attribute name", n.root)
+	}
+	field, known := fields[n.path[0]]
+	if known && len(n.path) == 1 {
+		return field.typ, nil
+	}
+	if known || n.root == "time" {
+		return typeAny, newExpressionError(n.source, n.at, "unknown attribute %s", n.name())
+	}
+	// Other fields of the subject and resource are their Attributes.
+	return typeAny, nil
+}
+
+func (n *attributeNode) eval(env *exprEnv) (any, error) {
+	switch n.root {
+	case "permission":
+		return string(env.request.Permission), nil
+	case "request":
+		var value any = env.request.Attributes
+		for _, key := range n.path {
+			value = lookupKey(value, key)
+		}
+		return normalize(value), nil
+	}
+
+	if field, ok := attributes[n.root][n.path[0]]; ok {
+		return field.value(env), nil
+	}
+	bag := &env.request.Subject.Attributes
+	if n.root == "resource" {
+		bag = &env.request.Resource.Attributes
+	}
+	value, _ := bag.Get(n.path[0])
+	for _, key := range n.path[1:] {
+		value = lookupKey(value, key)
+	}
+	return normalize(value), nil
+}
+
+func (n *attributeNode) pos() int { return n.at }
+
+// lookupKey returns the value under the key of a map with string keys, nil
+// when the key or the map is missing.
+func lookupKey(value any, key string) any {
+	if m, ok := value.(map[string]any); ok {
+		return m[key]
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	item := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+	if !item.IsValid() {
+		return nil
+	}
+	return item.Interface()
+}
+
+// normalize converts Go values to expression values: numbers to float64,
+// string types to string and slices to []any.
+func normalize(value any) any {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Slice, reflect.Array:
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = normalize(v.Index(i).Interface())
+		}
+		return list
+	}
+	return value
+}
+
+func typeOf(value any) exprType {
+	switch value.(type) {
+	case bool:
+		return typeBool
+	case float64:
+		return typeNumber
+	case string:
+		return typeString
+	case []any:
+		return typeList
+	}
+	return typeAny
+}
+
+// compatible reports whether a value of the actual type may be used where
+// the wanted type is expected.
+func compatible(actual, want exprType) bool {
+	return actual == typeAny || want == typeAny || actual == want
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+	at      int
+	source  string
+}
+
+func (n *unaryNode) check() (exprType, error) {
+	typ, err := n.operand.check()
+	if err != nil {
+		return typeAny, err
+	}
+	want := typeBool
+	if n.op == "-" {
+		want = typeNumber
+	}
+	if !compatible(typ, want) {
+		return typeAny, newExpressionError(n.source, n.at, "operator %s needs %s, got %s", n.op, want, typ)
+	}
+	return want, nil
+}
+
+func (n *unaryNode) eval(env *exprEnv) (any, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case bool:
+		if n.op == "!" {
+			return !v, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -v, nil
+		}
+	}
+	return nil, newEvaluationError(n.source, n.at, "operator %s cannot be applied to %s", n.op, typeOf(value))
+}
+
+func (n *unaryNode) pos() int { return n.at }
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+	at          int
+	source      string
+}
+
+func (n *binaryNode) check() (exprType, error) {
+	left, err := n.left.check()
+	if err != nil {
+		return typeAny, err
+	}
+	right, err := n.right.check()
+	if err != nil {
+		return typeAny, err
+	}
+
+	mismatch := func() (exprType, error) {
+		return typeAny, newExpressionError(n.source, n.at, "operator %s cannot be applied to %s and %s", n.op, left, right)
+	}
+
+	switch n.op {
+	case "&&", "||":
+		if !compatible(left, typeBool) || !compatible(right, typeBool) {
+			return mismatch()
+		}
+		return typeBool, nil
+	case "==", "!=":
+		if !compatible(left, right) {
+			return mismatch()
+		}
+		return typeBool, nil
+	case "<", "<=", ">", ">=":
+		if !compatible(left, right) || (left != typeAny && left != typeNumber && left != typeString) ||
+			(right != typeAny && right != typeNumber && right != typeString) {
+			return mismatch()
+		}
+		return typeBool, nil
+	case "in":
+		switch right {
+		case typeList, typeAny:
+		case typeString:
+			if !compatible(left, typeString) {
+				return mismatch()
+			}
+		default:
+			return mismatch()
+		}
+		return typeBool, nil
+	case "+":
+		if !compatible(left, right) || (left != typeAny && left != typeNumber && left != typeString) ||
+			(right != typeAny && right != typeNumber && right != typeString) {
+			return mismatch()
+		}
+		if left == typeAny {
+			return right, nil
+		}
+		return left, nil
+	default:
+		if !compatible(left, typeNumber) || !compatible(right, typeNumber) {
+			return mismatch()
+		}
+		return typeNumber, nil
+	}
+}
+
+func (n *binaryNode) eval(env *exprEnv) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, n.mismatch(left, nil)
+		}
+		if l == (n.op == "||") {
+			return l, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, n.mismatch(left, right)
+		}
+		return r, nil
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return reflect.DeepEqual(left, right), nil
+	case "!=":
+		return !reflect.DeepEqual(left, right), nil
+	case "in":
+		switch r := right.(type) {
+		case []any:
+			return slices.ContainsFunc(r, func(item any) bool { return reflect.DeepEqual(item, left) }), nil
+		case string:
+			if l, ok := left.(string); ok {
+				return strings.Contains(r, l), nil
+			}
+		case nil:
+			return false, nil
+		}
+		return nil, n.mismatch(left, right)
+	}
+
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			break
+		}
+		switch n.op {
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		case "/", "%":
+			if r == 0 {
+				return nil, newEvaluationError(n.source, n.at, "division by zero")
+			}
+			if n.op == "%" {
+				return math.Mod(l, r), nil
+			}
+			return l / r, nil
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			break
+		}
+		switch n.op {
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		case "+":
+			return l + r, nil
+		}
+	}
+	return nil, n.mismatch(left, right)
+}
+
+func (n *binaryNode) mismatch(left, right any) error {
+	return newEvaluationError(n.source, n.at, "operator %s cannot be applied to %s and %s", n.op, typeOf(left), typeOf(right))
+}
+
+func (n *binaryNode) pos() int { return n.at }
+
+// function is a built-in function, a parameter of typeAny accepts strings
+// and lists.
+type function struct {
+	params []exprType
+	result exprType
+	call   func(args []any) (any, bool)
+}
+
+var functions = map[string]function{
+	"lower": {[]exprType{typeString}, typeString, func(args []any) (any, bool) {
+		s, ok := args[0].(string)
+		return strings.ToLower(s), ok
+	}},
+	"upper": {[]exprType{typeString}, typeString, func(args []any) (any, bool) {
+		s, ok := args[0].(string)
+		return strings.ToUpper(s), ok
+	}},
+	"len": {[]exprType{typeAny}, typeNumber, func(args []any) (any, bool) {
+		switch v := args[0].(type) {
+		case string:
+			return float64(len([]rune(v))), true
+		case []any:
+			return float64(len(v)), true
+		case nil:
+			return float64(0), true
+		}
+		return nil, false
+	}},
+	"startsWith": {[]exprType{typeString, typeString}, typeBool, func(args []any) (any, bool) {
+		s, ok1 := args[0].(string)
+		prefix, ok2 := args[1].(string)
+		return strings.HasPrefix(s, prefix), ok1 && ok2
+	}},
+	"endsWith": {[]exprType{typeString, typeString}, typeBool, func(args []any) (any, bool) {
+		s, ok1 := args[0].(string)
+		suffix, ok2 := args[1].(string)
+		return strings.HasSuffix(s, suffix), ok1 && ok2
+	}},
+	"contains": {[]exprType{typeAny, typeAny}, typeBool, func(args []any) (any, bool) {
+		switch v := args[0].(type) {
+		case string:
+			sub, ok := args[1].(string)
+			return strings.Contains(v, sub), ok
+		case []any:
+			return slices.ContainsFunc(v, func(item any) bool { return reflect.DeepEqual(item, args[1]) }), true
+		case nil:
+			return false, true
+		}
+		return nil, false
+	}},
+}
+
+type callNode struct {
+	name   string
+	args   []exprNode
+	at     int
+	source string
+}
+
+func (n *callNode) check() (exprType, error) {
+	fn, ok := functions[n.name]
+	if !ok {
+		return typeAny, newExpressionError(n.source, n.at, "unknown function %s", n.name)
+	}
+	if len(n.args) != len(fn.params) {
+		return typeAny, newExpressionError(n.source, n.at, "%s takes %d arguments, got %d", n.name, len(fn.params), len(n.args))
+	}
+	for i, arg := range n.args {
+		typ, err := arg.check()
+		if err != nil {
+			return typeAny, err
+		}
+		want := fn.params[i]
+		if want == typeAny && i == 0 && typ != typeAny && typ != typeString && typ != typeList {
+			return typeAny, newExpressionError(n.source, arg.pos(), "%s needs string or list, got %s", n.name, typ)
+		}
+		if !compatible(typ, want) {
+			return typeAny, newExpressionError(n.source, arg.pos(), "%s needs %s, got %s", n.name, want, typ)
+		}
+	}
+	return fn.result, nil
+}
+
+func (n *callNode) eval(env *exprEnv) (any, error) {
+	args := make([]any, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	result, ok := functions[n.name].call(args)
+	if !ok {
+		types := make([]string, 0, len(args))
+		for _, arg := range args {
+			types = append(types, typeOf(arg).String())
+		}
+		return nil, newEvaluationError(n.source, n.at, "%s cannot be applied to %s", n.name, strings.Join(types, ", "))
+	}
+	return result, nil
+}
+
+func (n *callNode) pos() int { return n.at }
@@ -0,0 +1,331 @@
+package permission
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// twoCharOperators are the operators of two characters, matched before single ones.
+var twoCharOperators = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+// exprType is the static type of an expression, typeAny is checked at runtime.
+type exprType int
+
+const (
+	typeAny exprType = iota
+	typeBool
+	typeNumber
+	typeString
+	typeList
+)
+
+func (t exprType) String() string {
+	switch t {
+	case typeBool:
+		return "bool"
+	case typeNumber:
+		return "number"
+	case typeString:
+		return "string"
+	case typeList:
+		return "list"
+	default:
+		return "any"
+	}
+}
+
+// exprNode is a node of a compiled expression.
+type exprNode interface {
+	// check returns the static type of the node or an error for invalid operands.
+	check() (exprType, error)
+	// eval computes the value of the node.
+	eval(env *exprEnv) (any, error)
+	// pos is the position of the node in the source.
+	pos() int
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value any
+	pos   int
+}
+
+// lex splits an expression into tokens.
+func lex(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			value, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, newExpressionError(source, start, "invalid number %q", string(runes[start:i]))
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), value: value, pos: start})
+
+		case r == '"' || r == '\'':
+			start := i
+			var text strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, newExpressionError(source, start, "unterminated string")
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[start:i]), value: text.String(), pos: start})
+
+		default:
+			if i+1 < len(runes) {
+				if two := string(runes[i : i+2]); slices.Contains(twoCharOperators, two) {
+					tokens = append(tokens, token{kind: tokenOperator, text: two, pos: i})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("()[],.!<>+-*/%", r) {
+				return nil, newExpressionError(source, i, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r), pos: i})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// parser builds the syntax tree using precedence climbing:
+// || < && < comparison and in < + - < * / % < unary ! - < member access and calls.
+type parser struct {
+	source string
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) take() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) accept(kind tokenKind, texts ...string) (token, bool) {
+	t := p.peek()
+	if t.kind != kind || (len(texts) > 0 && !slices.Contains(texts, t.text)) {
+		return t, false
+	}
+	return p.take(), true
+}
+
+func (p *parser) expect(text string) error {
+	if _, ok := p.accept(tokenOperator, text); !ok {
+		return p.unexpected(text)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	return p.unexpectedToken(p.peek(), expected)
+}
+
+func (p *parser) unexpectedToken(t token, expected string) error {
+	if t.kind == tokenEOF {
+		return newExpressionError(p.source, t.pos, "unexpected end, expected %s", expected)
+	}
+	return newExpressionError(p.source, t.pos, "unexpected %q, expected %s", t.text, expected)
+}
+
+func (p *parser) parse() (exprNode, error) {
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected("end of expression")
+	}
+	return node, nil
+}
+
+func (p *parser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *parser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept(tokenOperator, "==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		op, ok = p.accept(tokenIdent, "in")
+	}
+	if !ok {
+		return left, nil
+	}
+
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{op: op.text, left: left, right: right, at: op.pos, source: p.source}, nil
+}
+
+func (p *parser) parseAdditive() (exprNode, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseBinary(operand func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(tokenOperator, ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, left: left, right: right, at: op.pos, source: p.source}
+	}
+}
+
+func (p *parser) parseUnary() (exprNode, error) {
+	if op, ok := p.accept(tokenOperator, "!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op.text, operand: operand, at: op.pos, source: p.source}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (exprNode, error) {
+	t := p.take()
+	switch t.kind {
+	case tokenNumber:
+		return &literalNode{value: t.value, typ: typeNumber, at: t.pos}, nil
+
+	case tokenString:
+		return &literalNode{value: t.value, typ: typeString, at: t.pos}, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			return &literalNode{value: t.text == "true", typ: typeBool, at: t.pos}, nil
+		}
+		if _, ok := p.accept(tokenOperator, "("); ok {
+			return p.parseCall(t)
+		}
+		return p.parseAttribute(t)
+
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "[":
+			return p.parseList(t)
+		}
+	}
+
+	return nil, p.unexpectedToken(t, "value")
+}
+
+func (p *parser) parseList(open token) (exprNode, error) {
+	list := &listNode{at: open.pos}
+	if _, ok := p.accept(tokenOperator, "]"); ok {
+		return list, nil
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		list.items = append(list.items, item)
+		if _, ok := p.accept(tokenOperator, ","); !ok {
+			return list, p.expect("]")
+		}
+	}
+}
+
+func (p *parser) parseCall(name token) (exprNode, error) {
+	call := &callNode{name: name.text, at: name.pos, source: p.source}
+	if _, ok := p.accept(tokenOperator, ")"); ok {
+		return call, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if _, ok := p.accept(tokenOperator, ","); !ok {
+			return call, p.expect(")")
+		}
+	}
+}
+
+func (p *parser) parseAttribute(root token) (exprNode, error) {
+	attribute := &attributeNode{root: root.text, at: root.pos, source: p.source}
+	for {
+		if _, ok := p.accept(tokenOperator, "."); !ok {
+			return attribute, nil
+		}
+		field, ok := p.accept(tokenIdent)
+		if !ok {
+			return nil, p.unexpected("attribute name")
+		}
+		attribute.path = append(attribute.path, field.text)
+	}
+}
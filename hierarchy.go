@@ -0,0 +1,237 @@
+package permission
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/gouef/utils"
+)
+
+// hierarchyMu serializes structural changes, so concurrent links cannot
+// together create a cycle that each of them alone would not.
+var hierarchyMu sync.Mutex
+
+// linkEntities connects parent and child in both directions.
+func linkEntities(parent, child *Entity) error {
+	defer child.touch()
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+
+	if parent == child || isEntityAncestor(child, parent) {
+		return &CycleError{Parent: parent.ID, Child: child.ID}
+	}
+	if parent.Tenant != "" && parent.Tenant != child.Tenant {
+		return fmt.Errorf("%w: %s of tenant %q under %s of tenant %q", ErrCrossTenant, child.ID, child.Tenant, parent.ID, parent.Tenant)
+	}
+
+	parent.mu.Lock()
+	if !utils.InArray(child, parent.Children) {
+		parent.Children = append(parent.Children, child)
+	}
+	parent.mu.Unlock()
+
+	child.mu.Lock()
+	if !utils.InArray(parent, child.Parents) {
+		child.Parents = append(child.Parents, parent)
+	}
+	child.mu.Unlock()
+
+	return nil
+}
+
+// linkResources makes sub a sub-resource of parent.
+func linkResources(parent, sub *Resource) error {
+	defer sub.touch()
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+
+	if parent == sub || isResourceAncestor(sub, parent) {
+		return &CycleError{Parent: parent.ID, Child: sub.ID}
+	}
+	if tenant := parent.GetTenant(); sub.Tenant != "" && tenant != "" && sub.Tenant != tenant {
+		return fmt.Errorf("%w: %s of tenant %q under %s of tenant %q", ErrCrossTenant, sub.ID, sub.Tenant, parent.ID, tenant)
+	}
+
+	oldRoot := rootResource(sub)
+	moved := subtreeRegistrations(sub, oldRoot)
+	defer func() { moveRegistrations(oldRoot, rootResource(sub), moved) }()
+
+	sub.mu.Lock()
+	previous := sub.Parent
+	sub.Parent = parent
+	sub.mu.Unlock()
+
+	if previous != nil && previous != parent {
+		previous.mu.Lock()
+		if previous.SubResources[sub.ID] == sub {
+			delete(previous.SubResources, sub.ID)
+		}
+		previous.mu.Unlock()
+	}
+
+	parent.mu.Lock()
+	if parent.SubResources == nil {
+		parent.SubResources = make(map[string]*Resource)
+	}
+	parent.SubResources[sub.ID] = sub
+	parent.mu.Unlock()
+
+	return nil
+}
+
+// unlinkEntities disconnects parent and child in both directions.
+func unlinkEntities(parent, child *Entity) {
+	defer child.touch()
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+
+	parent.mu.Lock()
+	parent.Children = slices.DeleteFunc(parent.Children, func(e *Entity) bool { return e == child })
+	parent.mu.Unlock()
+
+	child.mu.Lock()
+	child.Parents = slices.DeleteFunc(child.Parents, func(e *Entity) bool { return e == parent })
+	child.mu.Unlock()
+}
+
+// unlinkResources detaches sub from parent, making it a root resource.
+func unlinkResources(parent, sub *Resource) {
+	defer sub.touch()
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+
+	if sub.GetParent() != parent {
+		return
+	}
+	oldRoot := rootResource(sub)
+	moved := subtreeRegistrations(sub, oldRoot)
+	defer moveRegistrations(oldRoot, sub, moved)
+
+	sub.mu.Lock()
+	sub.Parent = nil
+	sub.mu.Unlock()
+
+	parent.mu.Lock()
+	if parent.SubResources[sub.ID] == sub {
+		delete(parent.SubResources, sub.ID)
+	}
+	parent.mu.Unlock()
+}
+
+// subtreeRegistrations counts the resources of the subtree of sub registered in
+// each AccessControl holding resources of the tree of root. The caller must
+// hold hierarchyMu.
+func subtreeRegistrations(sub, root *Resource) map[*AccessControl]int {
+	if sub == root || len(root.registrations) == 0 {
+		return maps.Clone(root.registrations)
+	}
+
+	resources := collectResources([]*Resource{sub})
+	moved := make(map[*AccessControl]int)
+	for ac := range root.registrations {
+		ac.mu.RLock()
+		for _, resource := range resources {
+			if ac.registered[resource] {
+				moved[ac]++
+			}
+		}
+		ac.mu.RUnlock()
+	}
+	return moved
+}
+
+// moveRegistrations moves counts of registered resources of a subtree from the
+// tree of oldRoot to the tree of newRoot, keeping roots of trees holding
+// registered resources in the registries. The caller must hold hierarchyMu.
+func moveRegistrations(oldRoot, newRoot *Resource, moved map[*AccessControl]int) {
+	if oldRoot == newRoot {
+		return
+	}
+	for ac, n := range moved {
+		if n == 0 {
+			continue
+		}
+		ac.mu.Lock()
+		if oldRoot.registrations[ac] -= n; oldRoot.registrations[ac] <= 0 {
+			delete(oldRoot.registrations, ac)
+			ac.dropRoot(oldRoot)
+		}
+		if newRoot.registrations == nil {
+			newRoot.registrations = make(map[*AccessControl]int)
+		}
+		newRoot.registrations[ac] += n
+		ac.addRoot(newRoot)
+		ac.mu.Unlock()
+	}
+}
+
+// collectEntities returns roots and every entity reachable from them through
+// Parents and Children, each once.
+func collectEntities(roots []*Entity) []*Entity {
+	visited := make(map[*Entity]bool)
+	var entities []*Entity
+	queue := append([]*Entity(nil), roots...)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == nil || visited[current] {
+			continue
+		}
+		visited[current] = true
+		entities = append(entities, current)
+		queue = append(queue, current.GetParents()...)
+		queue = append(queue, current.GetChildren()...)
+	}
+	return entities
+}
+
+// collectResources returns roots and all their descendants, each once.
+func collectResources(roots []*Resource) []*Resource {
+	visited := make(map[*Resource]bool)
+	var resources []*Resource
+	queue := append([]*Resource(nil), roots...)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == nil || visited[current] {
+			continue
+		}
+		visited[current] = true
+		resources = append(resources, current)
+		queue = append(queue, current.GetSubs()...)
+	}
+	return resources
+}
+
+// isEntityAncestor reports whether ancestor is reachable from entity through Parents.
+func isEntityAncestor(ancestor, entity *Entity) bool {
+	visited := map[*Entity]bool{entity: true}
+	queue := entity.GetParents()
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == ancestor {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		queue = append(queue, current.GetParents()...)
+	}
+	return false
+}
+
+// isResourceAncestor reports whether ancestor is reachable from resource through Parent.
+func isResourceAncestor(ancestor, resource *Resource) bool {
+	visited := map[*Resource]bool{resource: true}
+	for parent := resource.GetParent(); parent != nil && !visited[parent]; parent = parent.GetParent() {
+		if parent == ancestor {
+			return true
+		}
+		visited[parent] = true
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package permission
+
+import (
+	"slices"
+	"sort"
+)
+
+// Imply declares that holding a permission also gives the implied ones, so
+// checks of an implied permission consider allows of the implying one too.
+// Implications are transitive and carry only allows: a deny of a permission
+// does not deny the permissions it implies, a deny of All still does.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.Imply("MANAGE", permission.Update, permission.Delete)
+//	ac.Imply(permission.Update, permission.Read)
+//	ac.Allow(user, doc, permission.Update)
+//	ac.CanRead(user, doc) // true
+func (ac *AccessControl) Imply(permission Permission, implied ...Permission) *AccessControl {
+	defer ac.touch()
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.implications == nil {
+		ac.implications = make(map[Permission][]Permission)
+	}
+	for _, p := range implied {
+		if p != permission && !slices.Contains(ac.implications[permission], p) {
+			ac.implications[permission] = append(ac.implications[permission], p)
+		}
+	}
+	return ac
+}
+
+// DefineSet declares a named permission set, granting the set grants all its
+// members. A set is a permission implying its members, All is the built-in
+// set of every permission.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.DefineSet("editing", permission.Read, permission.Update)
+//	ac.Allow(user, doc, "editing")
+//	ac.CanUpdate(user, doc) // true
+func (ac *AccessControl) DefineSet(name Permission, members ...Permission) *AccessControl {
+	ac.Imply(name, members...)
+
+	defer ac.touch()
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if ac.sets == nil {
+		ac.sets = make(map[Permission]bool)
+	}
+	ac.sets[name] = true
+	return ac
+}
+
+// Sets returns the named permission sets with their members.
+//
+// Example:
+//
+//	ac.Sets() // map[editing:[READ UPDATE]]
+func (ac *AccessControl) Sets() map[Permission][]Permission {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	sets := make(map[Permission][]Permission, len(ac.sets))
+	for name := range ac.sets {
+		sets[name] = slices.Clone(ac.implications[name])
+	}
+	return sets
+}
+
+// Implications returns the declared implications, including sets.
+//
+// Example:
+//
+//	ac.Implications() // map[MANAGE:[UPDATE DELETE] UPDATE:[READ]]
+func (ac *AccessControl) Implications() map[Permission][]Permission {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	implications := make(map[Permission][]Permission, len(ac.implications))
+	for permission, implied := range ac.implications {
+		implications[permission] = slices.Clone(implied)
+	}
+	return implications
+}
+
+// Implies reports whether holding the permission gives the implied one,
+// directly or transitively. Every permission implies itself and All implies
+// every permission.
+//
+// Example:
+//
+//	ac.Imply("MANAGE", permission.Update)
+//	ac.Imply(permission.Update, permission.Read)
+//	ac.Implies("MANAGE", permission.Read) // true
+func (ac *AccessControl) Implies(permission, implied Permission) bool {
+	return permission == All || slices.Contains(ac.Expand(permission), implied)
+}
+
+// Expand returns the permission with every permission it implies. All expands
+// to all built-in permissions and permissions used in implications.
+//
+// Example:
+//
+//	ac.Expand("MANAGE") // [MANAGE UPDATE DELETE READ]
+func (ac *AccessControl) Expand(permission Permission) []Permission {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	if permission == All {
+		all := slices.Clone(builtinPermissions)
+		for p, implied := range ac.implications {
+			for _, q := range append([]Permission{p}, implied...) {
+				if !slices.Contains(all, q) {
+					all = append(all, q)
+				}
+			}
+		}
+		return all
+	}
+
+	expanded := []Permission{permission}
+	for i := 0; i < len(expanded); i++ {
+		for _, implied := range ac.implications[expanded[i]] {
+			if !slices.Contains(expanded, implied) {
+				expanded = append(expanded, implied)
+			}
+		}
+	}
+	return expanded
+}
+
+// implying returns the permissions whose rules decide a check of the
+// permission: the permission itself, every permission implying it (sorted)
+// and All.
+func (ac *AccessControl) implying(permission Permission) []Permission {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	implying := []Permission{permission}
+	var found []Permission
+	for i := 0; i < len(implying); i++ {
+		for p, implied := range ac.implications {
+			if slices.Contains(implied, implying[i]) && p != permission && !slices.Contains(implying, p) {
+				implying = append(implying, p)
+				found = append(found, p)
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+
+	permissions := append([]Permission{permission}, found...)
+	if permission != All && !slices.Contains(permissions, All) {
+		permissions = append(permissions, All)
+	}
+	return permissions
+}
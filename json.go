@@ -0,0 +1,81 @@
+package permission
+
+import "encoding/json"
+
+// MarshalJSON encodes registered entities and resources, together with every
+// entity and resource reachable from them, their hierarchies, ownership and
+// rules. Rules reference resources by path, so entity IDs and root resource
+// IDs must be unique.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	user := ac.CreateEntity("user1")
+//	doc := ac.CreateResource("document")
+//	ac.Allow(user, doc, permission.Read)
+//	data, err := json.Marshal(ac)
+//	// {"resources":[{"id":"document"}],"entities":[{"id":"user1","allow":{"READ":["document"]}}]}
+func (ac *AccessControl) MarshalJSON() ([]byte, error) {
+	doc, err := ac.document()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON replaces the content of the AccessControl with the graph
+// described by the JSON document produced by MarshalJSON.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	err := json.Unmarshal(data, ac)
+func (ac *AccessControl) UnmarshalJSON(data []byte) error {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	loaded := NewAccessControl()
+	if err := doc.build(loaded); err != nil {
+		return err
+	}
+
+	ac.replace(loaded)
+	return nil
+}
+
+// replace moves the content of other into the AccessControl.
+func (ac *AccessControl) replace(other *AccessControl) {
+	defer ac.touch()
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	for _, resource := range ac.Resources {
+		delete(rootResource(resource).registrations, ac)
+	}
+	for _, resource := range other.Resources {
+		root := rootResource(resource)
+		if n, ok := root.registrations[other]; ok {
+			root.registrations[ac] = n
+			delete(root.registrations, other)
+		}
+	}
+
+	ac.Entities = other.Entities
+	ac.Resources = other.Resources
+	ac.entities = other.entities
+	ac.resources = other.resources
+	ac.registered = other.registered
+	ac.roles = other.roles
+	ac.strategy = other.strategy
+	ac.implications = other.implications
+	ac.sets = other.sets
+	ac.declared = other.declared
+	ac.strict = other.strict
+	ac.types = other.types
+	ac.tuples = other.tuples
+	ac.relations = other.relations
+}
@@ -0,0 +1,233 @@
+package permission
+
+import (
+	"slices"
+	"sort"
+)
+
+// ListResourcesOptions filters the result of ListResources.
+type ListResourcesOptions struct {
+	// Root limits the result to the resource and its sub-resources.
+	Root *Resource
+	// Type limits the result to resources of the type.
+	Type string
+}
+
+// ListResources returns the registered resources and their sub-resources the
+// entity has the permission for, sorted by tenant and path. Only resources
+// within subtrees of allow rules, role assignments and ownership of the entity
+// or its ancestors are checked, each like HasPermission.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	group := ac.CreateEntity("group")
+//	user := group.CreateChild("user")
+//	docs := ac.CreateResource("docs")
+//	docs.CreateSubs("readme", "changelog")
+//	ac.Allow(group, docs, permission.Read)
+//	ac.ListResources(user, permission.Read, permission.ListResourcesOptions{}) // [docs docs/changelog docs/readme]
+func (ac *AccessControl) ListResources(entity *Entity, permission Permission, options ListResourcesOptions) []*Resource {
+	roots := []*Resource{options.Root}
+	if options.Root == nil {
+		roots = ac.rootResources()
+	}
+	resources := collectResources(roots)
+
+	seeds := ac.allowSeeds(entity, permission, resources)
+	var allowed []*Resource
+	for _, resource := range resources {
+		if options.Type != "" && resource.Type != options.Type {
+			continue
+		}
+		if !withinSeeds(resource, seeds) {
+			continue
+		}
+		if ac.HasPermission(entity, resource, permission) {
+			allowed = append(allowed, resource)
+		}
+	}
+	sortResources(allowed)
+	return allowed
+}
+
+// allowSeeds returns the resources which may allow the permission to the
+// entity, with their sub-resources: resources of concrete allow rules, role
+// scopes and grants, and resources matching allow patterns or owned by the
+// entity or its ancestors. A nil resource stands for every resource.
+func (ac *AccessControl) allowSeeds(entity *Entity, permission Permission, resources []*Resource) map[*Resource]bool {
+	permissions := ac.implying(permission)
+	seeds := make(map[*Resource]bool)
+	for _, e := range newEvaluation(entity, nil, permission, nil).entities {
+		for _, rule := range e.entity.rules() {
+			if !rule.Allow || !slices.Contains(permissions, rule.Permission) {
+				continue
+			}
+			if rule.Pattern == "" {
+				seeds[rule.Resource] = true
+				continue
+			}
+			for _, resource := range resources {
+				if MatchPattern(rule.Pattern, resource.Path()) {
+					seeds[resource] = true
+				}
+			}
+		}
+
+		for _, binding := range e.entity.GetRoles() {
+			seeds[binding.Scope] = true
+			for resource := range binding.Role.GetGrants() {
+				seeds[resource] = true
+			}
+		}
+
+		for _, resource := range resources {
+			if resource.isOwner(e.entity) {
+				seeds[resource] = true
+			}
+		}
+	}
+	return seeds
+}
+
+// withinSeeds reports whether the resource or one of its ancestors is a seed.
+func withinSeeds(resource *Resource, seeds map[*Resource]bool) bool {
+	if seeds[nil] {
+		return true
+	}
+	visited := make(map[*Resource]bool)
+	for current := resource; current != nil && !visited[current]; current = current.GetParent() {
+		if seeds[current] {
+			return true
+		}
+		visited[current] = true
+	}
+	return false
+}
+
+// rootResources returns the roots of registered resources, each once.
+func (ac *AccessControl) rootResources() []*Resource {
+	ac.mu.RLock()
+	registered := slices.Clone(ac.Resources)
+	ac.mu.RUnlock()
+
+	var roots []*Resource
+	for _, resource := range registered {
+		if root := rootResource(resource); !slices.Contains(roots, root) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// sortResources sorts resources by tenant and path.
+func sortResources(resources []*Resource) {
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if a.GetTenant() != b.GetTenant() {
+			return a.GetTenant() < b.GetTenant()
+		}
+		return a.Path() < b.Path()
+	})
+}
+
+// ListEntitiesOptions filters the result of ListEntities.
+type ListEntitiesOptions struct {
+	// Leaves limits the result to entities without children, expanding
+	// groups to their members.
+	Leaves bool
+}
+
+// ListEntities returns the entities with the permission for the resource,
+// sorted by tenant and ID: entities with allow rules, role assignments or
+// ownership of the resource or its ancestors, together with their descendants,
+// each checked like HasPermission.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	group := ac.CreateEntity("group")
+//	alice := group.CreateChild("alice")
+//	docs := ac.CreateResource("docs")
+//	ac.Allow(group, docs, permission.Delete)
+//	ac.ListEntities(docs, permission.Delete, permission.ListEntitiesOptions{})             // [alice group]
+//	ac.ListEntities(docs, permission.Delete, permission.ListEntitiesOptions{Leaves: true}) // [alice]
+func (ac *AccessControl) ListEntities(resource *Resource, permission Permission, options ListEntitiesOptions) []*Entity {
+	ac.mu.RLock()
+	registered := slices.Clone(ac.Entities)
+	ac.mu.RUnlock()
+
+	var ancestors []*Resource
+	visited := make(map[*Resource]bool)
+	for current := resource; current != nil && !visited[current]; current = current.GetParent() {
+		visited[current] = true
+		ancestors = append(ancestors, current)
+	}
+
+	permissions := ac.implying(permission)
+	var seeds []*Entity
+	for _, entity := range collectEntities(registered) {
+		if maySeed(entity, permissions, ancestors) {
+			seeds = append(seeds, entity)
+		}
+	}
+
+	var allowed []*Entity
+	for _, entity := range collectDescendants(seeds) {
+		if options.Leaves && len(entity.GetChildren()) > 0 {
+			continue
+		}
+		if ac.HasPermission(entity, resource, permission) {
+			allowed = append(allowed, entity)
+		}
+	}
+	sort.Slice(allowed, func(i, j int) bool {
+		a, b := allowed[i], allowed[j]
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.ID < b.ID
+	})
+	return allowed
+}
+
+// maySeed reports whether the entity has an allow rule for one of the
+// permissions, a role assignment or ownership on one of the resources.
+func maySeed(entity *Entity, permissions []Permission, resources []*Resource) bool {
+	for _, binding := range entity.GetRoles() {
+		if binding.Scope == nil || slices.Contains(resources, binding.Scope) {
+			return true
+		}
+	}
+	for _, resource := range resources {
+		if resource.isOwner(entity) {
+			return true
+		}
+		concrete, patterns := entity.matchingRules(permissions, resource, resource.Path())
+		for _, rule := range append(concrete, patterns...) {
+			if rule.Allow {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collectDescendants returns roots and every entity reachable from them
+// through Children, each once.
+func collectDescendants(roots []*Entity) []*Entity {
+	visited := make(map[*Entity]bool)
+	var entities []*Entity
+	queue := slices.Clone(roots)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		entities = append(entities, current)
+		queue = append(queue, current.GetChildren()...)
+	}
+	return entities
+}
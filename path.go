@@ -0,0 +1,128 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Path joins IDs of the resource and all its ancestors with "/".
+//
+// Example:
+//
+//	web := permission.NewResource("web")
+//	comment := web.CreateSub("comments").CreateSub("comment1")
+//	fmt.Println(comment.Path()) // Output: web/comments/comment1
+func (r *Resource) Path() string {
+	ids := []string{r.ID}
+	visited := map[*Resource]bool{r: true}
+	for parent := r.GetParent(); parent != nil && !visited[parent]; parent = parent.GetParent() {
+		visited[parent] = true
+		ids = append([]string{parent.ID}, ids...)
+	}
+	return strings.Join(ids, "/")
+}
+
+// GetSubPath retrieves a descendant by its path relative to the resource.
+//
+// Example:
+//
+//	web := permission.NewResource("web")
+//	web.CreateSub("comments").CreateSub("comment1")
+//	fmt.Println(web.GetSubPath("comments/comment1").ID) // Output: comment1
+func (r *Resource) GetSubPath(path string) *Resource {
+	resource := r
+	for _, id := range splitPath(path) {
+		if resource = resource.GetSub(id); resource == nil {
+			return nil
+		}
+	}
+	return resource
+}
+
+// ResolveResource finds a resource by its path, e.g. "web/comments/comment1".
+// Empty segments are ignored, so URL paths like "/web/comments/" resolve too.
+// Sub-resources of registered resources are resolved through SubResources.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.CreateResource("web").CreateSub("comments").CreateSub("comment1")
+//	res, err := ac.ResolveResource("/web/comments/comment1")
+func (ac *AccessControl) ResolveResource(path string) (*Resource, error) {
+	return ac.resolveResource("", path)
+}
+
+// resolveResource finds a resource by path in the registry of a tenant.
+func (ac *AccessControl) resolveResource(tenant, path string) (*Resource, error) {
+	segments := splitPath(path)
+	path = strings.Join(segments, "/")
+
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	if len(segments) > 0 {
+		// Global trees may hold sub-resources of a tenant.
+		for _, rootTenant := range slices.Compact([]string{tenant, ""}) {
+			root := ac.registeredRoot(registryKey{tenant: rootTenant, id: segments[0]})
+			if root == nil {
+				continue
+			}
+			resource := root
+			if len(segments) > 1 {
+				resource = root.GetSubPath(strings.Join(segments[1:], "/"))
+			}
+			if resource != nil && resource.GetTenant() == tenant {
+				return resource, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, registryKey{tenant: tenant, id: path})
+}
+
+// CanPath checks if an entity has a specific permission for the resource at
+// the path. It returns false when the path does not resolve.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	user := ac.CreateEntity("user1")
+//	web := ac.CreateResource("web")
+//	web.CreateSub("comments")
+//	ac.Allow(user, web, permission.Read)
+//	fmt.Println(ac.CanPath(user, "web/comments", permission.Read)) // Output: true
+func (ac *AccessControl) CanPath(entity *Entity, path string, permission Permission) bool {
+	resource, err := ac.ResolveResource(path)
+	if err != nil {
+		return false
+	}
+	return ac.Can(entity, resource, permission)
+}
+
+// ExplainPath is like Explain for the resource at the path.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	user := ac.CreateEntity("user1")
+//	ac.CreateResource("web")
+//	decision, err := ac.ExplainPath(user, "web", permission.Read)
+func (ac *AccessControl) ExplainPath(entity *Entity, path string, permission Permission) (Decision, error) {
+	resource, err := ac.ResolveResource(path)
+	if err != nil {
+		return Decision{Entity: entity}, err
+	}
+	return ac.Explain(entity, resource, permission), nil
+}
+
+// splitPath splits a resource path into IDs, ignoring empty segments.
+func splitPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
@@ -0,0 +1,65 @@
+package permission
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// IsPattern reports whether a resource path contains wildcards: "*" matches
+// any part of a single segment, "?" a single character, "[...]" a character
+// class and a whole "**" segment matches any number of segments.
+//
+// Example:
+//
+//	fmt.Println(permission.IsPattern("web/comments/*")) // Output: true
+//	fmt.Println(permission.IsPattern("web/comments"))   // Output: false
+func IsPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// ValidatePattern checks the syntax of a resource pattern.
+//
+// Example:
+//
+//	err := permission.ValidatePattern("reports/2026-[") // errors.Is(err, permission.ErrBadPattern)
+func ValidatePattern(pattern string) error {
+	for _, segment := range splitPath(pattern) {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("%w: %s", ErrBadPattern, pattern)
+		}
+	}
+	return nil
+}
+
+// MatchPattern reports whether a resource path matches the pattern.
+//
+// Example:
+//
+//	fmt.Println(permission.MatchPattern("web/**", "web/comments/comment1")) // Output: true
+//	fmt.Println(permission.MatchPattern("reports/2026-*", "reports/2026-01")) // Output: true
+func MatchPattern(pattern string, resourcePath string) bool {
+	return matchSegments(splitPath(pattern), splitPath(resourcePath))
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(segments); i >= 0; i-- {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -15,3 +15,6 @@ const (
 	// All grants full access to a resource.
 	All Permission = "ALL"
 )
+
+// builtinPermissions lists permissions defined by the package.
+var builtinPermissions = []Permission{Create, Read, Update, Delete, All}
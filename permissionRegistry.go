@@ -0,0 +1,139 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// PermissionInfo describes a declared permission.
+type PermissionInfo struct {
+	Permission  Permission
+	Description string
+	Category    string
+}
+
+// DeclarePermission adds a permission to the registry of the AccessControl,
+// declaring it again replaces its description and category. Built-in
+// permissions and permission sets are always known.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.DeclarePermission("vote", "Vote in polls", "community")
+func (ac *AccessControl) DeclarePermission(permission Permission, description, category string) *AccessControl {
+	defer ac.touch()
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.declared == nil {
+		ac.declared = make(map[Permission]PermissionInfo)
+	}
+	ac.declared[permission] = PermissionInfo{Permission: permission, Description: description, Category: category}
+	return ac
+}
+
+// Permissions returns the declared permissions sorted by category and name.
+//
+// Example:
+//
+//	for _, info := range ac.Permissions() {
+//		fmt.Println(info.Category, info.Permission, info.Description)
+//	}
+func (ac *AccessControl) Permissions() []PermissionInfo {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	permissions := make([]PermissionInfo, 0, len(ac.declared))
+	for _, info := range ac.declared {
+		permissions = append(permissions, info)
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		a, b := permissions[i], permissions[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Permission < b.Permission
+	})
+	return permissions
+}
+
+// ValidatePermission checks that the permission is built-in, declared or a
+// permission set.
+//
+// Example:
+//
+//	if err := ac.ValidatePermission("REDA"); err != nil {
+//		// errors.Is(err, permission.ErrUnknownPermission)
+//	}
+func (ac *AccessControl) ValidatePermission(permission Permission) error {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	if !ac.knownPermission(permission) {
+		return fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+	}
+	return nil
+}
+
+// WithStrictPermissions enables the strict mode, see SetStrictPermissions.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl(permission.WithStrictPermissions())
+func WithStrictPermissions() Option {
+	return func(ac *AccessControl) {
+		ac.strict = true
+	}
+}
+
+// SetStrictPermissions enables or disables the strict mode. In the strict mode
+// only built-in, declared and set permissions are accepted: Allow, Deny and
+// pattern rules panic and ValidateGrant fails for other permissions, checks of
+// other permissions are denied with ErrUnknownPermission in Decision.Err.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.SetStrictPermissions(true)
+//	ac.Allow(user, doc, "REDA") // panics
+func (ac *AccessControl) SetStrictPermissions(strict bool) *AccessControl {
+	defer ac.touch()
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.strict = strict
+	return ac
+}
+
+// StrictPermissions reports whether the strict mode is enabled.
+func (ac *AccessControl) StrictPermissions() bool {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.strict
+}
+
+// strictPermissionError returns the error of ValidatePermission in the strict
+// mode, nil otherwise.
+func (ac *AccessControl) strictPermissionError(permission Permission) error {
+	if !ac.StrictPermissions() {
+		return nil
+	}
+	return ac.ValidatePermission(permission)
+}
+
+// mustValidatePermission panics when strictPermissionError fails.
+func (ac *AccessControl) mustValidatePermission(permission Permission) {
+	if err := ac.strictPermissionError(permission); err != nil {
+		panic(err)
+	}
+}
+
+// knownPermission reports whether the permission is built-in, declared or a
+// set. The caller must hold ac.mu.
+func (ac *AccessControl) knownPermission(permission Permission) bool {
+	if slices.Contains(builtinPermissions, permission) || ac.sets[permission] {
+		return true
+	}
+	_, ok := ac.declared[permission]
+	return ok
+}
@@ -0,0 +1,179 @@
+package permission
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadPolicyYAML creates an AccessControl from a YAML policy declaring
+// permissions, resource trees with owners and entities with parents and
+// allow/deny rules. Unknown keys, invalid references and duplicate IDs are
+// reported as *PolicyError with the line of the offending entry.
+//
+// Example:
+//
+//	policy := `
+//	permissions: [vote]
+//	resources:
+//	  - id: web
+//	    resources:
+//	      - id: comments
+//	entities:
+//	  - id: editors
+//	    allow:
+//	      UPDATE: [web/comments]
+//	  - id: alice
+//	    parents: [editors]
+//	    allow:
+//	      vote: [web]
+//	`
+//	ac, err := permission.LoadPolicyYAML(strings.NewReader(policy))
+func LoadPolicyYAML(r io.Reader) (*AccessControl, error) {
+	var node yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&node); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	var doc document
+	if err := checkFields(&node, reflect.TypeOf(doc)); err != nil {
+		return nil, err
+	}
+	if node.Kind != 0 {
+		if err := node.Decode(&doc); err != nil {
+			return nil, err
+		}
+	}
+
+	ac := NewAccessControl()
+	if err := doc.build(ac); err != nil {
+		return nil, err
+	}
+	return ac, nil
+}
+
+// ValidatePolicyYAML checks a YAML policy without keeping the result.
+//
+// Example:
+//
+//	if err := permission.ValidatePolicyYAML(file); err != nil {
+//		log.Fatal(err) // permission: policy line 12: parent of alice: permission: entity not found: admins
+//	}
+func ValidatePolicyYAML(r io.Reader) error {
+	_, err := LoadPolicyYAML(r)
+	return err
+}
+
+// checkFields reports the first key of a mapping which is not a field of the
+// struct it decodes into. Custom UnmarshalYAML methods decode with a new
+// decoder, so yaml.Decoder.KnownFields does not reach them.
+func checkFields(node *yaml.Node, t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			if err := checkFields(content, t); err != nil {
+				return err
+			}
+		}
+	case yaml.AliasNode:
+		return checkFields(node.Alias, t)
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		for _, content := range node.Content {
+			if err := checkFields(content, t.Elem()); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			switch {
+			case t.Kind() == reflect.Map:
+				if err := checkFields(value, t.Elem()); err != nil {
+					return err
+				}
+			case t.Kind() == reflect.Struct:
+				field, ok := yamlField(t, key.Value)
+				if !ok {
+					return atLine(key.Line, fmt.Errorf("%w: %s", ErrUnknownField, key.Value))
+				}
+				if err := checkFields(value, field); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// yamlField returns the type of the struct field decoded from the key.
+func yamlField(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == key {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+func (doc *permissionDocument) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*doc = permissionDocument{}
+		return node.Decode(&doc.Name)
+	}
+	type plain permissionDocument
+	return node.Decode((*plain)(doc))
+}
+
+func (doc *resourceDocument) UnmarshalYAML(node *yaml.Node) error {
+	type plain resourceDocument
+	if err := node.Decode((*plain)(doc)); err != nil {
+		return err
+	}
+	doc.line = node.Line
+	return nil
+}
+
+func (doc *entityDocument) UnmarshalYAML(node *yaml.Node) error {
+	type plain entityDocument
+	if err := node.Decode((*plain)(doc)); err != nil {
+		return err
+	}
+	doc.line = node.Line
+	return nil
+}
+
+func (doc *ruleDocument) UnmarshalYAML(node *yaml.Node) error {
+	type plain ruleDocument
+	if err := node.Decode((*plain)(doc)); err != nil {
+		return err
+	}
+	doc.line = node.Line
+	return nil
+}
+
+func (doc *roleDocument) UnmarshalYAML(node *yaml.Node) error {
+	type plain roleDocument
+	if err := node.Decode((*plain)(doc)); err != nil {
+		return err
+	}
+	doc.line = node.Line
+	return nil
+}
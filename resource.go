@@ -1,11 +1,34 @@
 package permission
 
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
 // Resource represents an entity that can be assigned permissions.
+//
+// Methods of Resource are safe for concurrent use. Fields should not be modified
+// directly once the resource is shared between goroutines.
 type Resource struct {
-	ID           string
+	ID string
+	// Tenant scopes the resource tree to a tenant, see GetTenant.
+	Tenant string
+	// Type names the resource type limiting applicable permissions, see
+	// AccessControl.DefineResourceType.
+	Type         string
 	Parent       *Resource
 	SubResources map[string]*Resource // Podresource podle názvu
 	Owners       []*Entity            // Vlastníci resource
+	// Attributes holds metadata of the resource, like a classification level.
+	Attributes Attributes
+
+	// version counts changes, see decisionCache.
+	version atomic.Uint64
+	// registrations counts, on a root resource, the resources of its tree
+	// registered in each AccessControl. It is guarded by hierarchyMu.
+	registrations map[*AccessControl]int
+	mu            sync.RWMutex
 }
 
 // NewResource initializes a new resource with the given ID.
@@ -45,9 +68,55 @@ func (r *Resource) CreateSub(id string) *Resource {
 //	news := website.CreateSub("news")
 //	fmt.Println(website.GetSub("news").ID) // Output: news
 func (r *Resource) GetSub(id string) *Resource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.SubResources[id]
 }
 
+// GetParent returns the parent resource or nil for a root resource.
+//
+// Example:
+//
+//	website := permission.NewResource("website")
+//	news := website.CreateSub("news")
+//	fmt.Println(news.GetParent().ID) // Output: website
+func (r *Resource) GetParent() *Resource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.Parent
+}
+
+// GetSubs returns a snapshot of the sub-resources.
+//
+// Example:
+//
+//	website := permission.NewResource("website")
+//	website.CreateSubs("news", "comments")
+//	fmt.Println(len(website.GetSubs())) // Output: 2
+func (r *Resource) GetSubs() []*Resource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := make([]*Resource, 0, len(r.SubResources))
+	for _, sub := range r.SubResources {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// GetOwners returns a snapshot of the resource owners.
+//
+// Example:
+//
+//	user := permission.NewEntity("user")
+//	doc := permission.NewResource("document").AddOwners(user)
+//	fmt.Println(doc.GetOwners()[0].ID) // Output: user
+func (r *Resource) GetOwners() []*Entity {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*Entity(nil), r.Owners...)
+}
+
 // CreateSubs generates multiple sub-resources.
 //
 // Example:
@@ -62,15 +131,36 @@ func (r *Resource) CreateSubs(ids ...string) *Resource {
 }
 
 // AddSubs links additional sub-resources to the current resource.
+// It panics with a *CycleError when a sub-resource is an ancestor of the
+// resource, use AttachSubs to get an error instead.
 func (r *Resource) AddSubs(resources ...*Resource) *Resource {
-	for _, resource := range resources {
-		resource.Parent = r
-		r.SubResources[resource.ID] = resource
+	if err := r.AttachSubs(resources...); err != nil {
+		panic(err)
 	}
 
 	return r
 }
 
+// AttachSubs links additional sub-resources to the current resource.
+// It stops at the first sub-resource that would create a cycle and returns a *CycleError,
+// or that belongs to another tenant and returns an error wrapping ErrCrossTenant.
+//
+// Example:
+//
+//	website := permission.NewResource("website")
+//	if err := website.AttachSubs(permission.NewResource("news")); err != nil {
+//		// errors.Is(err, permission.ErrCycle)
+//	}
+func (r *Resource) AttachSubs(resources ...*Resource) error {
+	for _, resource := range resources {
+		if err := linkResources(r, resource); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // AddOwners assigns ownership of the resource to specific entities.
 //
 // Example:
@@ -79,6 +169,69 @@ func (r *Resource) AddSubs(resources ...*Resource) *Resource {
 //	doc := permission.NewResource("document")
 //	doc.AddOwners(user)
 func (r *Resource) AddOwners(owners ...*Entity) *Resource {
+	defer r.touch()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.Owners = append(r.Owners, owners...)
 	return r
 }
+
+// RemoveOwners removes ownership of the resource from specific entities.
+//
+// Example:
+//
+//	user := permission.NewEntity("user")
+//	doc := permission.NewResource("document").AddOwners(user)
+//	doc.RemoveOwners(user)
+func (r *Resource) RemoveOwners(owners ...*Entity) *Resource {
+	defer r.touch()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Owners = slices.DeleteFunc(r.Owners, func(owner *Entity) bool {
+		return slices.Contains(owners, owner)
+	})
+	return r
+}
+
+// RemoveSub detaches the sub-resource with the given ID.
+//
+// Example:
+//
+//	website := permission.NewResource("website")
+//	website.CreateSubs("news", "comments")
+//	website.RemoveSub("news")
+func (r *Resource) RemoveSub(id string) *Resource {
+	if sub := r.GetSub(id); sub != nil {
+		r.RemoveSubs(sub)
+	}
+	return r
+}
+
+// RemoveSubs detaches sub-resources, they become root resources.
+//
+// Example:
+//
+//	website := permission.NewResource("website")
+//	news := website.CreateSub("news")
+//	website.RemoveSubs(news)
+func (r *Resource) RemoveSubs(resources ...*Resource) *Resource {
+	for _, resource := range resources {
+		unlinkResources(r, resource)
+	}
+	return r
+}
+
+// isOwner reports whether the entity is one of the resource owners.
+func (r *Resource) isOwner(entity *Entity) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, owner := range r.Owners {
+		if owner == entity {
+			return true
+		}
+	}
+	return false
+}
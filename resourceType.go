@@ -0,0 +1,133 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ResourceType declares the permissions applicable to resources of a type and
+// the relations they support, like "owner" or "viewer".
+type ResourceType struct {
+	Name        string
+	Permissions []Permission
+	Relations   []string
+}
+
+// DefineResourceType declares a resource type, defining it again replaces it.
+// Resources of a defined type only accept rules for applicable permissions:
+// the permissions of the type, All and permissions implying one of them.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.DefineResourceType("document", []permission.Permission{permission.Read, permission.Update, "SHARE"}, []string{"owner", "viewer"})
+//	ac.DefineResourceType("billing", []permission.Permission{"VIEW", "PAY"}, nil)
+//	account := ac.CreateResource("acme-billing")
+//	account.Type = "billing"
+//	ac.Allow(user, account, permission.Read) // panics
+func (ac *AccessControl) DefineResourceType(name string, permissions []Permission, relations []string) *AccessControl {
+	defer ac.touch()
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.types == nil {
+		ac.types = make(map[string]ResourceType)
+	}
+	ac.types[name] = ResourceType{Name: name, Permissions: slices.Clone(permissions), Relations: slices.Clone(relations)}
+	return ac
+}
+
+// GetResourceType finds a defined resource type by its name.
+//
+// Example:
+//
+//	document, err := ac.GetResourceType("document")
+func (ac *AccessControl) GetResourceType(name string) (ResourceType, error) {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	if resourceType, ok := ac.types[name]; ok {
+		return ResourceType{Name: name, Permissions: slices.Clone(resourceType.Permissions), Relations: slices.Clone(resourceType.Relations)}, nil
+	}
+	return ResourceType{}, fmt.Errorf("%w: %s", ErrResourceTypeNotFound, name)
+}
+
+// ResourceTypes returns the defined resource types sorted by name.
+//
+// Example:
+//
+//	for _, resourceType := range ac.ResourceTypes() {
+//		fmt.Println(resourceType.Name, resourceType.Permissions)
+//	}
+func (ac *AccessControl) ResourceTypes() []ResourceType {
+	ac.mu.RLock()
+	names := make([]string, 0, len(ac.types))
+	for name := range ac.types {
+		names = append(names, name)
+	}
+	ac.mu.RUnlock()
+
+	sort.Strings(names)
+	types := make([]ResourceType, 0, len(names))
+	for _, name := range names {
+		if resourceType, err := ac.GetResourceType(name); err == nil {
+			types = append(types, resourceType)
+		}
+	}
+	return types
+}
+
+// PermissionsFor returns the permissions applicable to the resource: the
+// permissions of its type, or for a resource without a defined type the
+// built-in permissions other than All followed by the declared ones.
+//
+// Example:
+//
+//	for _, p := range ac.PermissionsFor(doc) {
+//		fmt.Println(p) // READ, UPDATE, SHARE
+//	}
+func (ac *AccessControl) PermissionsFor(resource *Resource) []Permission {
+	if resourceType, err := ac.GetResourceType(resource.Type); err == nil {
+		return resourceType.Permissions
+	}
+
+	permissions := []Permission{Create, Read, Update, Delete}
+	for _, info := range ac.Permissions() {
+		if !slices.Contains(permissions, info.Permission) {
+			permissions = append(permissions, info.Permission)
+		}
+	}
+	return permissions
+}
+
+// RelationsFor returns the relations of the type of the resource, nil for a
+// resource without a defined type.
+//
+// Example:
+//
+//	ac.RelationsFor(doc) // [owner viewer]
+func (ac *AccessControl) RelationsFor(resource *Resource) []string {
+	if resourceType, err := ac.GetResourceType(resource.Type); err == nil {
+		return resourceType.Relations
+	}
+	return nil
+}
+
+// inapplicableError reports a permission which does not apply to the type of
+// the resource, nil when it applies or the type is not defined.
+func (ac *AccessControl) inapplicableError(resource *Resource, permission Permission) error {
+	if resource.Type == "" || permission == All {
+		return nil
+	}
+	resourceType, err := ac.GetResourceType(resource.Type)
+	if err != nil {
+		return nil
+	}
+	for _, p := range ac.Expand(permission) {
+		if slices.Contains(resourceType.Permissions, p) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s on %s of type %s", ErrInapplicablePermission, permission, resource.Path(), resource.Type)
+}
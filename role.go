@@ -0,0 +1,346 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Role is a named set of permissions assigned to entities with AssignRole,
+// separately from the membership of entities in groups. Its permissions apply
+// to every resource in the scope of an assignment, its grants only to the
+// granted resources (and their sub-resources) within the scope. Roles only
+// allow, an assignment never denies.
+//
+// Methods of Role are safe for concurrent use.
+type Role struct {
+	ID string
+
+	permissions []Permission
+	grants      map[*Resource][]Permission
+	// version counts changes, see decisionCache.
+	version atomic.Uint64
+	mu      sync.RWMutex
+}
+
+// RoleBinding is an assignment of a role to an entity, limited to the Scope
+// resource and its sub-resources. A nil Scope means every resource.
+type RoleBinding struct {
+	Role  *Role
+	Scope *Resource
+}
+
+// NewRole creates a role with resource-independent permissions.
+//
+// Example:
+//
+//	editor := permission.NewRole("editor", permission.Read, permission.Update)
+func NewRole(id string, permissions ...Permission) *Role {
+	role := &Role{ID: id, grants: make(map[*Resource][]Permission)}
+	role.Allow(permissions...)
+	return role
+}
+
+// Allow adds resource-independent permissions to the role.
+//
+// Example:
+//
+//	editor := permission.NewRole("editor")
+//	editor.Allow(permission.Read, permission.Update)
+func (r *Role) Allow(permissions ...Permission) *Role {
+	defer r.touch()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, permission := range permissions {
+		if !slices.Contains(r.permissions, permission) {
+			r.permissions = append(r.permissions, permission)
+		}
+	}
+	return r
+}
+
+// AllowOn adds permissions bound to a resource and its sub-resources.
+//
+// Example:
+//
+//	billing := permission.NewResource("billing")
+//	accountant := permission.NewRole("accountant", permission.Read)
+//	accountant.AllowOn(billing, permission.Update)
+func (r *Role) AllowOn(resource *Resource, permissions ...Permission) *Role {
+	defer r.touch()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.grants == nil {
+		r.grants = make(map[*Resource][]Permission)
+	}
+	for _, permission := range permissions {
+		if !slices.Contains(r.grants[resource], permission) {
+			r.grants[resource] = append(r.grants[resource], permission)
+		}
+	}
+	return r
+}
+
+// Revoke removes resource-independent permissions from the role.
+//
+// Example:
+//
+//	editor.Revoke(permission.Update)
+func (r *Role) Revoke(permissions ...Permission) *Role {
+	defer r.touch()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.permissions = slices.DeleteFunc(r.permissions, func(p Permission) bool { return slices.Contains(permissions, p) })
+	return r
+}
+
+// RevokeOn removes permissions bound to a resource.
+//
+// Example:
+//
+//	accountant.RevokeOn(billing, permission.Update)
+func (r *Role) RevokeOn(resource *Resource, permissions ...Permission) *Role {
+	defer r.touch()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining := slices.DeleteFunc(r.grants[resource], func(p Permission) bool { return slices.Contains(permissions, p) })
+	if len(remaining) == 0 {
+		delete(r.grants, resource)
+	} else {
+		r.grants[resource] = remaining
+	}
+	return r
+}
+
+// GetPermissions returns a snapshot of the resource-independent permissions.
+//
+// Example:
+//
+//	editor.GetPermissions() // [READ UPDATE]
+func (r *Role) GetPermissions() []Permission {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.permissions)
+}
+
+// GetGrants returns a snapshot of the permissions bound to resources.
+//
+// Example:
+//
+//	for resource, permissions := range accountant.GetGrants() {
+//		fmt.Println(resource.Path(), permissions)
+//	}
+func (r *Role) GetGrants() map[*Resource][]Permission {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	grants := make(map[*Resource][]Permission, len(r.grants))
+	for resource, permissions := range r.grants {
+		grants[resource] = slices.Clone(permissions)
+	}
+	return grants
+}
+
+// allows returns the first of the permissions the role grants on the
+// resource, or its resource-independent permissions for a nil resource.
+func (r *Role) allows(permissions []Permission, resource *Resource) (Permission, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	granted := r.permissions
+	if resource != nil {
+		granted = r.grants[resource]
+	}
+	for _, permission := range permissions {
+		if slices.Contains(granted, permission) {
+			return permission, true
+		}
+	}
+	return "", false
+}
+
+// AssignRole assigns the role to the entity within the scope resource and
+// its sub-resources, nil scope means every resource. Members (children) of the
+// entity inherit the assignment.
+//
+// Example:
+//
+//	editors := permission.NewEntity("editors")
+//	docs := permission.NewResource("docs")
+//	editors.AssignRole(permission.NewRole("editor", permission.Update), docs)
+func (e *Entity) AssignRole(role *Role, scope *Resource) {
+	defer e.touch()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	binding := RoleBinding{Role: role, Scope: scope}
+	if !slices.Contains(e.roles, binding) {
+		e.roles = append(e.roles, binding)
+	}
+}
+
+// UnassignRole removes the assignment of the role in the scope.
+//
+// Example:
+//
+//	editors.UnassignRole(editor, docs)
+func (e *Entity) UnassignRole(role *Role, scope *Resource) {
+	defer e.touch()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.roles = slices.DeleteFunc(e.roles, func(b RoleBinding) bool { return b.Role == role && b.Scope == scope })
+}
+
+// GetRoles returns a snapshot of roles assigned directly to the entity.
+//
+// Example:
+//
+//	for _, binding := range user.GetRoles() {
+//		fmt.Println(binding.Role.ID)
+//	}
+func (e *Entity) GetRoles() []RoleBinding {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return slices.Clone(e.roles)
+}
+
+// roleRules returns allow rules of roles assigned to the entity which grant
+// one of the permissions on resources[depth]. Unscoped permissions of a role
+// are attached to the root resource, scoped ones to the scope.
+func (e *Entity) roleRules(permissions []Permission, resources []*Resource, depth int) []Rule {
+	resource := resources[depth]
+	var rules []Rule
+	for _, binding := range e.GetRoles() {
+		scopeDepth := len(resources) - 1
+		if binding.Scope != nil {
+			scopeDepth = slices.Index(resources, binding.Scope)
+			if scopeDepth < 0 {
+				continue
+			}
+		}
+
+		if depth > scopeDepth {
+			continue
+		}
+
+		permission, ok := binding.Role.allows(permissions, resource)
+		if !ok && depth == scopeDepth {
+			permission, ok = binding.Role.allows(permissions, nil)
+		}
+		if ok {
+			rules = append(rules, Rule{Entity: e, Resource: resource, Role: binding.Role, Permission: permission, Allow: true})
+		}
+	}
+	return rules
+}
+
+// CreateRole creates a new role and registers it.
+// It panics when a role with the same ID is already registered.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	editor := ac.CreateRole("editor", permission.Read, permission.Update)
+func (ac *AccessControl) CreateRole(id string, permissions ...Permission) *Role {
+	role := NewRole(id, permissions...)
+	if err := ac.RegisterRole(role); err != nil {
+		panic(err)
+	}
+	return role
+}
+
+// RegisterRole registers a role, registering the same role twice is a no-op.
+//
+// Example:
+//
+//	err := ac.RegisterRole(permission.NewRole("viewer", permission.Read))
+func (ac *AccessControl) RegisterRole(role *Role) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.roles == nil {
+		ac.roles = make(map[string]*Role)
+	}
+	if existing, ok := ac.roles[role.ID]; ok {
+		if existing == role {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrDuplicateRole, role.ID)
+	}
+	ac.roles[role.ID] = role
+	return nil
+}
+
+// GetRole finds a registered role by its ID.
+//
+// Example:
+//
+//	editor, err := ac.GetRole("editor")
+func (ac *AccessControl) GetRole(id string) (*Role, error) {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	if role, ok := ac.roles[id]; ok {
+		return role, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, id)
+}
+
+// Roles returns the registered roles sorted by ID.
+//
+// Example:
+//
+//	for _, role := range ac.Roles() {
+//		fmt.Println(role.ID)
+//	}
+func (ac *AccessControl) Roles() []*Role {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	roles := make([]*Role, 0, len(ac.roles))
+	for _, role := range ac.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ID < roles[j].ID })
+	return roles
+}
+
+// AssignRole assigns the role to the entity within the scope resource and its
+// sub-resources, nil scope means every resource. The role is registered when
+// needed. It panics when the scope belongs to another tenant.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	editor := ac.CreateRole("editor", permission.Read, permission.Update)
+//	user := ac.CreateEntity("user1")
+//	docs := ac.CreateResource("docs")
+//	ac.AssignRole(user, editor, docs)
+//	ac.CanUpdate(user, docs.CreateSub("readme")) // true
+func (ac *AccessControl) AssignRole(entity *Entity, role *Role, scope *Resource) *AccessControl {
+	if scope != nil {
+		ac.mustValidateGrant(entity, scope, "")
+	}
+	if err := ac.RegisterRole(role); err != nil {
+		panic(err)
+	}
+	entity.AssignRole(role, scope)
+	return ac
+}
+
+// UnassignRole removes the assignment of the role to the entity in the scope.
+//
+// Example:
+//
+//	ac.UnassignRole(user, editor, docs)
+func (ac *AccessControl) UnassignRole(entity *Entity, role *Role, scope *Resource) *AccessControl {
+	entity.UnassignRole(role, scope)
+	return ac
+}
@@ -0,0 +1,199 @@
+package permission
+
+import "fmt"
+
+// Strategy decides which of the rules applicable to a permission check wins.
+//
+// Rules are collected from the checked entity and its ancestors, visited
+// breadth-first with parents in the order they were added, and for each of
+// them from the checked resource up to its root. A rule is more specific when
+// its entity is nearer to the checked entity, or for the same distance when its
+// resource is nearer to the checked resource. Ownership always allows
+// regardless of the strategy.
+type Strategy int
+
+const (
+	// MostSpecificWins applies the most specific rules. When rules of the same
+	// specificity disagree, deny wins. This is the default.
+	MostSpecificWins Strategy = iota
+	// DenyOverrides denies when any applicable rule denies.
+	DenyOverrides
+	// AllowOverrides allows when any applicable rule allows.
+	AllowOverrides
+	// FirstApplicable applies the first rule in evaluation order.
+	FirstApplicable
+	// AnyPathAllows allows when walking from the checked entity and resource
+	// to parents, one entity or resource step at a time, reaches ownership or
+	// an allowing rule before any denying one. Of the rules of the same entity
+	// and resource the most specific one applies. This is the behavior before
+	// strategies were introduced.
+	AnyPathAllows
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case MostSpecificWins:
+		return "most-specific-wins"
+	case DenyOverrides:
+		return "deny-overrides"
+	case AllowOverrides:
+		return "allow-overrides"
+	case FirstApplicable:
+		return "first-applicable"
+	case AnyPathAllows:
+		return "any-path-allows"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the strategy as its name.
+func (s Strategy) MarshalText() ([]byte, error) {
+	if s.String() == "unknown" {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownStrategy, int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a strategy from its name, e.g. "deny-overrides".
+func (s *Strategy) UnmarshalText(text []byte) error {
+	for _, strategy := range []Strategy{MostSpecificWins, DenyOverrides, AllowOverrides, FirstApplicable, AnyPathAllows} {
+		if strategy.String() == string(text) {
+			*s = strategy
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownStrategy, text)
+}
+
+// resolve picks the deciding rule among candidates sorted in evaluation order.
+// AnyPathAllows needs the hierarchy, see evaluation.anyPathAllows.
+func (s Strategy) resolve(candidates []candidate) *candidate {
+	switch s {
+	case DenyOverrides:
+		return firstWith(candidates, false, candidates)
+	case AllowOverrides:
+		return firstWith(candidates, true, candidates)
+	case FirstApplicable:
+		if len(candidates) == 0 {
+			return nil
+		}
+		return &candidates[0]
+	default:
+		var nearest []candidate
+		for _, c := range candidates {
+			switch {
+			case len(nearest) == 0 || c.moreSpecific(nearest[0]):
+				nearest = []candidate{c}
+			case !nearest[0].moreSpecific(c):
+				nearest = append(nearest, c)
+			}
+		}
+		return firstWith(nearest, false, nearest)
+	}
+}
+
+// anyPathAllows walks from the checked entity and resource to parent entities
+// first and then to the parent resource, stopping at an entity owning the
+// resource or at the rules of an entity and resource. It returns the owner and
+// the owned resource, or the first allowing rule reached, or the first denying
+// one when no path allows.
+func (ev *evaluation) anyPathAllows(candidates []candidate) (*candidate, *Entity, *Resource) {
+	type node struct {
+		entity *Entity
+		depth  int
+	}
+	rules := make(map[node][]candidate)
+	for _, c := range candidates {
+		n := node{entity: c.rule.Entity, depth: c.resourceDepth}
+		rules[n] = append(rules[n], c)
+	}
+
+	var denied, allowed *candidate
+	var owner *Entity
+	var owned *Resource
+	visited := make(map[node]bool)
+	var visit func(n node) bool
+	visit = func(n node) bool {
+		if n.depth >= len(ev.resources) || visited[n] {
+			return false
+		}
+		visited[n] = true
+		if resource := ev.resources[n.depth]; resource.isOwner(n.entity) {
+			owner, owned = n.entity, resource
+			return true
+		}
+		if c := MostSpecificWins.resolve(rules[n]); c != nil {
+			if c.rule.Allow {
+				allowed = c
+				return true
+			}
+			if denied == nil {
+				denied = c
+			}
+			return false
+		}
+		for _, parent := range n.entity.GetParents() {
+			if visit(node{entity: parent, depth: n.depth}) {
+				return true
+			}
+		}
+		return visit(node{entity: n.entity, depth: n.depth + 1})
+	}
+
+	if len(ev.entities) > 0 && visit(node{entity: ev.entities[0].entity}) {
+		return allowed, owner, owned
+	}
+	return denied, nil, nil
+}
+
+// firstWith returns the first candidate with the given result, or the first
+// candidate of fallback when there is none.
+func firstWith(candidates []candidate, allow bool, fallback []candidate) *candidate {
+	for i := range candidates {
+		if candidates[i].rule.Allow == allow {
+			return &candidates[i]
+		}
+	}
+	if len(fallback) == 0 {
+		return nil
+	}
+	return &fallback[0]
+}
+
+// Option configures an AccessControl created by NewAccessControl.
+type Option func(ac *AccessControl)
+
+// WithStrategy sets the conflict-resolution strategy.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl(permission.WithStrategy(permission.DenyOverrides))
+func WithStrategy(strategy Strategy) Option {
+	return func(ac *AccessControl) {
+		ac.strategy = strategy
+	}
+}
+
+// SetStrategy changes the conflict-resolution strategy.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.SetStrategy(permission.AllowOverrides)
+func (ac *AccessControl) SetStrategy(strategy Strategy) *AccessControl {
+	defer ac.touch()
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.strategy = strategy
+	return ac
+}
+
+// Strategy returns the conflict-resolution strategy.
+func (ac *AccessControl) Strategy() Strategy {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	return ac.strategy
+}
@@ -0,0 +1,211 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// registryKey identifies a registered entity or root resource by ID within a
+// tenant, the empty tenant holds global entities and resources.
+type registryKey struct {
+	tenant string
+	id     string
+}
+
+// matchesRoot reports whether the resource is currently a root with the key.
+func (k registryKey) matchesRoot(resource *Resource) bool {
+	return resource.GetParent() == nil && resource.ID == k.id && resource.Tenant == k.tenant
+}
+
+func (k registryKey) String() string {
+	if k.tenant == "" {
+		return k.id
+	}
+	return k.tenant + ":" + k.id
+}
+
+// GetTenant returns the tenant of the resource, inherited from the nearest
+// ancestor with a tenant. It is empty for a global resource.
+//
+// Example:
+//
+//	docs := permission.NewResource("docs")
+//	docs.Tenant = "acme"
+//	docs.CreateSub("report").GetTenant() // acme
+func (r *Resource) GetTenant() string {
+	visited := make(map[*Resource]bool)
+	for current := r; current != nil && !visited[current]; current = current.GetParent() {
+		if current.Tenant != "" {
+			return current.Tenant
+		}
+		visited[current] = true
+	}
+	return ""
+}
+
+// CreateEntityInTenant creates a new entity in the tenant and adds it to the
+// system. It panics when the tenant already has an entity with the same ID.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	alice := ac.CreateEntityInTenant("acme", "alice")
+func (ac *AccessControl) CreateEntityInTenant(tenant, id string) *Entity {
+	entity := NewEntity(id)
+	entity.Tenant = tenant
+	ac.AddEntity(entity)
+	return entity
+}
+
+// CreateResourceInTenant creates a new root resource in the tenant and adds
+// it to the system. It panics when the tenant already has a resource with the
+// same path.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	docs := ac.CreateResourceInTenant("acme", "docs")
+func (ac *AccessControl) CreateResourceInTenant(tenant, id string) *Resource {
+	resource := NewResource(id)
+	resource.Tenant = tenant
+	ac.AddResource(resource)
+	return resource
+}
+
+// GetEntityInTenant finds a registered entity of the tenant by its ID.
+//
+// Example:
+//
+//	alice, err := ac.GetEntityInTenant("acme", "alice")
+func (ac *AccessControl) GetEntityInTenant(tenant, id string) (*Entity, error) {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	key := registryKey{tenant: tenant, id: id}
+	if entity, ok := ac.entities[key]; ok {
+		return entity, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrEntityNotFound, key)
+}
+
+// GetResourceInTenant finds a resource of the tenant by its path.
+//
+// Example:
+//
+//	report, err := ac.GetResourceInTenant("acme", "docs/report")
+func (ac *AccessControl) GetResourceInTenant(tenant, path string) (*Resource, error) {
+	return ac.resolveResource(tenant, path)
+}
+
+// Tenants returns the sorted names of tenants with registered entities or
+// resources.
+//
+// Example:
+//
+//	ac.Tenants() // [acme globex]
+func (ac *AccessControl) Tenants() []string {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	var tenants []string
+	for key := range ac.entities {
+		if key.tenant != "" && !slices.Contains(tenants, key.tenant) {
+			tenants = append(tenants, key.tenant)
+		}
+	}
+	for _, resource := range ac.Resources {
+		if tenant := resource.GetTenant(); tenant != "" && !slices.Contains(tenants, tenant) {
+			tenants = append(tenants, tenant)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// CanInTenant checks a permission within a tenant. It is false when the entity
+// or the resource belongs to another tenant. Global entities and resources are
+// part of every tenant.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	editors := ac.CreateEntity("editors")
+//	alice := ac.CreateEntityInTenant("acme", "alice")
+//	alice.AddParents(editors)
+//	docs := ac.CreateResourceInTenant("acme", "docs")
+//	ac.AllowPattern(editors, "docs/**", permission.Update)
+//	ac.CanInTenant("acme", alice, docs, permission.Update) // true
+func (ac *AccessControl) CanInTenant(tenant string, entity *Entity, resource *Resource, permission Permission) bool {
+	if !inTenant(entity.Tenant, tenant) || !inTenant(resource.GetTenant(), tenant) {
+		return false
+	}
+	return ac.Can(entity, resource, permission)
+}
+
+// ValidateGrant checks that a rule of the entity for the resource can be set.
+// A tenant entity may only get rules for global resources and resources of
+// its tenant, a global entity only for global resources, use patterns to give
+// global entities access within each tenant. The permission must apply to
+// the type of the resource, see DefineResourceType, and in the strict mode it
+// must be known, see SetStrictPermissions.
+//
+// Example:
+//
+//	if err := ac.ValidateGrant(alice, report, permission.Read); err != nil {
+//		// errors.Is(err, permission.ErrCrossTenant)
+//	}
+func (ac *AccessControl) ValidateGrant(entity *Entity, resource *Resource, permission Permission) error {
+	if tenant := resource.GetTenant(); tenant != "" && tenant != entity.Tenant {
+		return fmt.Errorf("%w: %s of tenant %q on %s of tenant %q", ErrCrossTenant, entity.ID, entity.Tenant, resource.Path(), tenant)
+	}
+	if permission == "" {
+		return nil
+	}
+	if err := ac.strictPermissionError(permission); err != nil {
+		return err
+	}
+	return ac.inapplicableError(resource, permission)
+}
+
+// mustValidateGrant panics when ValidateGrant fails.
+func (ac *AccessControl) mustValidateGrant(entity *Entity, resource *Resource, permission Permission) {
+	if err := ac.ValidateGrant(entity, resource, permission); err != nil {
+		panic(err)
+	}
+}
+
+// crossTenantError reports a check of an entity for a resource of another tenant.
+func crossTenantError(entity *Entity, resource *Resource) error {
+	if tenant := resource.GetTenant(); entity.Tenant != "" && tenant != "" && entity.Tenant != tenant {
+		return fmt.Errorf("%w: %s of tenant %q on %s of tenant %q", ErrCrossTenant, entity.ID, entity.Tenant, resource.Path(), tenant)
+	}
+	return nil
+}
+
+// inTenant reports whether something of the owner tenant is visible in the tenant.
+func inTenant(owner, tenant string) bool {
+	return owner == "" || owner == tenant
+}
+
+// lookupEntity finds an entity of the tenant, falling back to a global one.
+func (ac *AccessControl) lookupEntity(tenant, id string) (*Entity, error) {
+	entity, err := ac.GetEntityInTenant(tenant, id)
+	if err != nil && tenant != "" {
+		if global, globalErr := ac.GetEntity(id); globalErr == nil {
+			return global, nil
+		}
+	}
+	return entity, err
+}
+
+// lookupResource finds a resource of the tenant, falling back to a global one.
+func (ac *AccessControl) lookupResource(tenant, path string) (*Resource, error) {
+	resource, err := ac.GetResourceInTenant(tenant, path)
+	if err != nil && tenant != "" {
+		if global, globalErr := ac.GetResource(path); globalErr == nil {
+			return global, nil
+		}
+	}
+	return resource, err
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Typed accessors", func(t *testing.T) {
+		user := permission.NewEntity("alice")
+		user.Attributes.Set("department", "sales")
+		user.Attributes.Set("level", 3)
+		user.Attributes.Set("score", 4.5)
+		user.Attributes.Set("imported", 7.0)
+		user.Attributes.Set("verified", true)
+		user.Attributes.Set("regions", []string{"eu", "us"})
+		user.Attributes.Set("mixed", []any{"eu", 1})
+
+		department, ok := user.Attributes.String("department")
+		assert.True(t, ok)
+		assert.Equal(t, "sales", department)
+
+		level, ok := user.Attributes.Int("level")
+		assert.True(t, ok)
+		assert.Equal(t, 3, level)
+
+		imported, ok := user.Attributes.Int("imported")
+		assert.True(t, ok, "whole floats are integers")
+		assert.Equal(t, 7, imported)
+
+		_, ok = user.Attributes.Int("score")
+		assert.False(t, ok)
+
+		score, ok := user.Attributes.Float("score")
+		assert.True(t, ok)
+		assert.Equal(t, 4.5, score)
+
+		verified, ok := user.Attributes.Bool("verified")
+		assert.True(t, ok)
+		assert.True(t, verified)
+
+		regions, ok := user.Attributes.Strings("regions")
+		assert.True(t, ok)
+		assert.Equal(t, []string{"eu", "us"}, regions)
+
+		_, ok = user.Attributes.Strings("mixed")
+		assert.False(t, ok)
+
+		_, ok = user.Attributes.String("level")
+		assert.False(t, ok, "wrong type")
+		_, ok = user.Attributes.String("missing")
+		assert.False(t, ok)
+
+		value, ok := user.Attributes.Get("level")
+		assert.True(t, ok)
+		assert.Equal(t, 3, value)
+		assert.Equal(t, []string{"department", "imported", "level", "mixed", "regions", "score", "verified"}, user.Attributes.Keys())
+
+		user.Attributes.Delete("mixed")
+		assert.False(t, user.Attributes.Has("mixed"))
+		assert.Len(t, user.Attributes.Map(), 6)
+
+		assert.Nil(t, permission.NewResource("web").Attributes.Map())
+	})
+
+	t.Run("Concurrent access", func(t *testing.T) {
+		doc := permission.NewResource("doc")
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				doc.Attributes.Set("level", i)
+				doc.Attributes.Int("level")
+				doc.Attributes.Map()
+			}(i)
+		}
+		wg.Wait()
+		assert.True(t, doc.Attributes.Has("level"))
+	})
+
+	t.Run("Conditions", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		reports := ac.CreateResource("reports")
+		secret := reports.CreateSub("secret")
+		secret.Attributes.Set("classification", "secret")
+		public := reports.CreateSub("public")
+		staff := ac.CreateEntity("staff")
+		alice := staff.CreateChild("alice")
+		alice.Attributes.Set("department", "sales")
+		alice.Attributes.Set("clearance", 2)
+		bob := staff.CreateChild("bob")
+
+		ac.AllowIf(staff, reports, permission.Read, permission.SubjectAttrEquals("department", "sales"))
+		ac.DenyIf(staff, secret, permission.Read, permission.ResourceAttrEquals("classification", "secret"))
+		ac.AllowIf(staff, reports, permission.Update, permission.SubjectAttrEquals("clearance", 2.0))
+
+		assert.True(t, ac.CanRead(alice, public))
+		assert.False(t, ac.CanRead(alice, secret))
+		assert.False(t, ac.CanRead(bob, public))
+		assert.True(t, ac.CanUpdate(alice, public), "numbers compare regardless of type")
+	})
+
+	t.Run("Expressions", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		docs := ac.CreateResource("docs")
+		doc := docs.CreateSub("doc1")
+		doc.Attributes.Set("owner", "alice")
+		doc.Attributes.Set("level", 2)
+		doc.Attributes.Set("meta", map[string]any{"region": "eu"})
+		users := ac.CreateEntity("users")
+		alice := users.CreateChild("alice")
+		alice.Attributes.Set("clearance", 3)
+		alice.Attributes.Set("regions", []string{"eu"})
+		bob := users.CreateChild("bob")
+
+		ac.AllowIf(users, docs, permission.Update, permission.MustCompile(`resource.owner == subject.id`))
+		ac.AllowIf(users, docs, permission.Read, permission.MustCompile(`subject.clearance >= resource.level && resource.meta.region in subject.regions`))
+
+		assert.True(t, ac.CanUpdate(alice, doc))
+		assert.False(t, ac.CanUpdate(bob, doc))
+		assert.True(t, ac.CanRead(alice, doc))
+		assert.False(t, ac.CanWithContext(ctx, bob, doc, permission.Read, nil))
+	})
+
+	t.Run("Serialization", func(t *testing.T) {
+		policy := `
+resources:
+  - id: docs
+    attributes:
+      classification: internal
+      level: 2
+entities:
+  - id: alice
+    attributes:
+      department: sales
+      regions: [eu, us]
+`
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(policy))
+		if !assert.NoError(t, err) {
+			return
+		}
+		alice := ac.MustGetEntity("alice")
+		regions, _ := alice.Attributes.Strings("regions")
+		assert.Equal(t, []string{"eu", "us"}, regions)
+		level, _ := ac.MustGetResource("docs").Attributes.Int("level")
+		assert.Equal(t, 2, level)
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"resources": [{"id": "docs", "attributes": {"classification": "internal", "level": 2}}],
+			"entities": [{"id": "alice", "attributes": {"department": "sales", "regions": ["eu", "us"]}}]
+		}`, string(data))
+
+		restored := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, restored))
+		level, ok := restored.MustGetResource("docs").Attributes.Int("level")
+		assert.True(t, ok)
+		assert.Equal(t, 2, level)
+		department, _ := restored.MustGetEntity("alice").Attributes.String("department")
+		assert.Equal(t, "sales", department)
+	})
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionCache(t *testing.T) {
+
+	t.Run("Hits and misses", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithDecisionCache())
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+		ac.Allow(user, doc, permission.Read)
+
+		assert.True(t, ac.CanRead(user, doc))
+		assert.True(t, ac.CanRead(user, doc))
+		assert.False(t, ac.CanUpdate(user, doc))
+		assert.Equal(t, permission.CacheStats{Hits: 1, Misses: 2, Entries: 2}, ac.CacheStats())
+		assert.Equal(t, "allowed: allow READ for user on doc", ac.Explain(user, doc, permission.Read).String())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+
+		ac.CanRead(user, doc)
+		assert.Equal(t, permission.CacheStats{}, ac.CacheStats())
+
+		ac.SetDecisionCache(true)
+		ac.CanRead(user, doc)
+		ac.CanRead(user, doc)
+		assert.Equal(t, uint64(1), ac.CacheStats().Hits)
+
+		ac.SetDecisionCache(false)
+		assert.Equal(t, permission.CacheStats{}, ac.CacheStats())
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithDecisionCache())
+		group := ac.CreateEntity("group")
+		user := ac.CreateEntity("user")
+		docs := ac.CreateResource("docs")
+		readme := ac.CreateResource("readme")
+
+		assert.False(t, ac.CanRead(user, readme))
+		user.AddPerm(permission.Read, readme, true)
+		assert.True(t, ac.CanRead(user, readme), "AddPerm")
+
+		ac.Allow(group, docs, permission.Update)
+		assert.False(t, ac.CanUpdate(user, readme))
+		assert.NoError(t, user.AddParents(group))
+		assert.False(t, ac.CanUpdate(user, readme))
+		docs.AddSubs(readme)
+		assert.True(t, ac.CanUpdate(user, readme), "hierarchy links")
+
+		user.RemoveParents(group)
+		assert.False(t, ac.CanUpdate(user, readme))
+		readme.AddOwners(user)
+		assert.True(t, ac.CanUpdate(user, readme), "owners")
+		readme.RemoveOwners(user)
+		assert.False(t, ac.CanUpdate(user, readme))
+
+		role := ac.CreateRole("editor")
+		ac.AssignRole(user, role, nil)
+		assert.False(t, ac.CanDelete(user, readme))
+		role.Allow(permission.Delete)
+		assert.True(t, ac.CanDelete(user, readme), "roles")
+
+		ac.Imply(permission.Delete, permission.Create)
+		assert.True(t, ac.CanCreate(user, readme), "implications")
+
+		ac.Deny(user, readme, permission.Create)
+		ac.SetStrategy(permission.AllowOverrides)
+		assert.True(t, ac.CanCreate(user, readme), "strategy")
+	})
+
+	t.Run("Unrelated changes keep decisions", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithDecisionCache())
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+		ac.Allow(user, doc, permission.Read)
+		assert.True(t, ac.CanRead(user, doc))
+
+		other := permission.NewAccessControl()
+		ac.Allow(other.CreateEntity("user"), other.CreateResource("doc"), permission.Read)
+		stranger := ac.CreateEntity("stranger")
+		ac.Allow(stranger, doc, permission.Update)
+		ac.CreateResource("wiki").AddOwners(stranger)
+		permission.NewRole("viewer").Allow(permission.Read)
+
+		assert.True(t, ac.CanRead(user, doc))
+		assert.Equal(t, permission.CacheStats{Hits: 1, Misses: 1, Entries: 1}, ac.CacheStats())
+	})
+
+	t.Run("Assigned fields", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithDecisionCache())
+		ac.DefineResourceType("billing", []permission.Permission{"PAY"}, nil)
+		user := ac.CreateEntity("user")
+		docs := ac.CreateResource("docs")
+		readme := docs.CreateSub("readme")
+		ac.Allow(user, docs, permission.Read)
+
+		assert.True(t, ac.CanRead(user, readme))
+		readme.Type = "billing"
+		assert.False(t, ac.CanRead(user, readme))
+		readme.Type = ""
+
+		assert.True(t, ac.CanRead(user, readme))
+		readme.Tenant = "acme"
+		assert.False(t, ac.CanRead(user, readme))
+	})
+
+	t.Run("Size", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithDecisionCacheSize(2))
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+
+		ac.CanRead(user, doc)
+		ac.CanUpdate(user, doc)
+		ac.CanDelete(user, doc)
+		assert.Equal(t, 2, ac.CacheStats().Entries)
+		assert.False(t, ac.CanDelete(user, doc))
+		assert.Equal(t, uint64(1), ac.CacheStats().Hits)
+	})
+
+	t.Run("Conditions are not cached", func(t *testing.T) {
+		now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		ac := permission.NewAccessControl(permission.WithDecisionCache(), permission.WithClock(func() time.Time { return now }))
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+		ac.AllowIf(user, doc, permission.Read, permission.Validity{NotAfter: time.Date(2026, 1, 1, 13, 0, 0, 0, time.UTC)})
+
+		assert.True(t, ac.CanRead(user, doc))
+		now = now.Add(2 * time.Hour)
+		assert.False(t, ac.CanRead(user, doc))
+		assert.Equal(t, 0, ac.CacheStats().Entries)
+
+		other := ac.CreateResource("other")
+		ac.DenyIf(user, other, permission.Read, permission.RequestAttrEquals("blocked", true))
+		assert.False(t, ac.CanWithContext(nil, user, other, permission.Read, map[string]any{"blocked": true}))
+		ac.Allow(user, other, permission.Update)
+		assert.True(t, ac.CanUpdate(user, other))
+		assert.Equal(t, 1, ac.CacheStats().Entries)
+	})
+
+	t.Run("Purging keeps decisions of unchanged entities", func(t *testing.T) {
+		now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		ac := permission.NewAccessControl(permission.WithDecisionCache(), permission.WithClock(func() time.Time { return now }))
+		user := ac.CreateEntity("user")
+		contractor := ac.CreateEntity("contractor")
+		doc := ac.CreateResource("doc")
+		ac.Allow(user, doc, permission.Read)
+		ac.AllowIf(contractor, doc, permission.Read, permission.Validity{NotAfter: now.Add(time.Hour)})
+
+		assert.True(t, ac.CanRead(user, doc))
+		now = now.Add(2 * time.Hour)
+		assert.Equal(t, 1, ac.PurgeExpired())
+		assert.True(t, ac.CanRead(user, doc))
+		assert.Equal(t, permission.CacheStats{Hits: 1, Misses: 1, Entries: 1}, ac.CacheStats())
+	})
+
+	t.Run("Concurrent checks and mutations", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithDecisionCache())
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					ac.CanRead(user, doc)
+				}
+			}()
+		}
+		ac.Allow(user, doc, permission.Read)
+		wg.Wait()
+		assert.True(t, ac.CanRead(user, doc))
+	})
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrency(t *testing.T) {
+
+	t.Run("Check while mutating", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		ac.AddEntity(user)
+		ac.Allow(group, web, permission.Read)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					ac.Can(user, comments, permission.Read)
+					ac.CanUpdate(user, comments)
+				}
+			}()
+			go func(i int) {
+				defer wg.Done()
+				ac.Allow(user, comments, permission.Update)
+				ac.Deny(group, web, permission.Delete)
+				member := permission.NewEntity(fmt.Sprintf("member%d", i))
+				group.AddChildren(member)
+				ac.AddEntity(member)
+				comments.CreateSub(fmt.Sprintf("comment%d", i)).AddOwners(member)
+			}(i)
+			go func(i int) {
+				defer wg.Done()
+				_, _ = ac.GetEntity(fmt.Sprintf("member%d", i))
+				_, _ = ac.GetResource(fmt.Sprintf("web/comments/comment%d", i))
+				user.GetParents()
+				group.GetChildren()
+				comments.GetSubs()
+			}(i)
+		}
+		wg.Wait()
+
+		assert.True(t, ac.CanRead(user, comments))
+		assert.True(t, ac.CanUpdate(user, comments))
+		assert.False(t, ac.CanDelete(user, comments))
+		assert.Len(t, group.GetChildren(), 51)
+		assert.Len(t, comments.GetSubs(), 50)
+		assert.True(t, ac.CanDelete(ac.MustGetEntity("member7"), ac.MustGetResource("web/comments/comment7")))
+	})
+}
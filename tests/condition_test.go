@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Conditional allow", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		articles := ac.CreateResource("articles")
+		article := articles.CreateSub("article1")
+		editors := ac.CreateEntity("editors")
+		user := editors.CreateChild("user")
+		ac.AllowIf(editors, articles, permission.Update, permission.RequestAttrEquals("status", "draft"))
+
+		assert.True(t, ac.CanWithContext(ctx, user, article, permission.Update, map[string]any{"status": "draft"}))
+		assert.False(t, ac.CanWithContext(ctx, user, article, permission.Update, map[string]any{"status": "published"}))
+		assert.False(t, ac.CanUpdate(user, article), "conditions without attributes are not met")
+
+		decision := ac.ExplainWithContext(ctx, user, article, permission.Update, map[string]any{"status": "draft"})
+		assert.Len(t, decision.Rule.Conditions, 1)
+		assert.Equal(t, "allowed: allow UPDATE for editors on articles if conditions are met", decision.String())
+
+		ac.Allow(editors, articles, permission.Update)
+		assert.True(t, ac.CanUpdate(user, article), "unconditional rule replaces the conditional one")
+	})
+
+	t.Run("Condition receives the checked request", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comment := web.CreateSub("comment")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+
+		var received *permission.Request
+		group.AddPermIf(permission.Read, web, true, permission.ConditionFunc(func(ctx context.Context, r *permission.Request) (bool, error) {
+			received = r
+			return true, nil
+		}))
+
+		assert.True(t, ac.CanWithContext(ctx, user, comment, permission.Read, map[string]any{"a": 1}))
+		assert.Same(t, user, received.Subject)
+		assert.Same(t, comment, received.Resource)
+		assert.Equal(t, permission.Read, received.Permission)
+		assert.Equal(t, map[string]any{"a": 1}, received.Attributes)
+	})
+
+	t.Run("Office IP range", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		reports := ac.CreateResource("reports")
+		users := ac.CreateEntity("users")
+		office := permission.RequestIPIn("ip", netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.0/24"))
+		ac.AllowIf(users, reports, permission.Read, office)
+
+		assert.True(t, ac.CanWithContext(ctx, users, reports, permission.Read, map[string]any{"ip": "10.1.2.3"}))
+		assert.True(t, ac.CanWithContext(ctx, users, reports, permission.Read, map[string]any{"ip": net.ParseIP("192.168.1.7")}))
+		assert.True(t, ac.CanWithContext(ctx, users, reports, permission.Read, map[string]any{"ip": netip.MustParseAddr("10.0.0.1")}))
+		assert.False(t, ac.CanWithContext(ctx, users, reports, permission.Read, map[string]any{"ip": "8.8.8.8"}))
+
+		decision := ac.ExplainWithContext(ctx, users, reports, permission.Read, map[string]any{"ip": "not-an-ip"})
+		assert.False(t, decision.Allowed)
+		assert.ErrorIs(t, decision.Err, permission.ErrCondition)
+	})
+
+	t.Run("Failing condition never allows", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		user := ac.CreateEntity("user")
+		failing := permission.ConditionFunc(func(ctx context.Context, r *permission.Request) (bool, error) {
+			return false, errors.New("backend down")
+		})
+
+		ac.Allow(user, web, permission.Read)
+		ac.DenyIf(user, web, permission.Read, failing)
+		assert.False(t, ac.CanRead(user, web))
+
+		ac.AllowIf(user, web, permission.Update, failing)
+		assert.False(t, ac.CanUpdate(user, web))
+	})
+
+	t.Run("Conditional pattern and revocation", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		reports := ac.CreateResource("reports")
+		report := reports.CreateSub("2026-01")
+		users := ac.CreateEntity("users")
+		users.AddPermPatternIf(permission.Read, "reports/*", true, permission.RequestAttrEquals("vpn", true))
+
+		assert.True(t, ac.CanWithContext(ctx, users, report, permission.Read, map[string]any{"vpn": true}))
+		assert.False(t, ac.CanWithContext(ctx, users, report, permission.Read, map[string]any{"vpn": false}))
+
+		users.AddPermIf(permission.Update, report, true, permission.RequestAttrEquals("vpn", true))
+		users.Revoke(report, permission.Update)
+		users.AddPerm(permission.Update, report, true)
+		assert.True(t, ac.CanUpdate(users, report), "revocation removes conditions")
+	})
+
+	t.Run("Go conditions cannot be serialized", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		user := ac.CreateEntity("user")
+		ac.AllowIf(user, web, permission.Read, permission.RequestAttrEquals("a", 1))
+
+		_, err := json.Marshal(ac)
+		assert.ErrorIs(t, err, permission.ErrUnserializableCondition)
+	})
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+
+	t.Run("Explicit deny on entity", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		ac.Allow(group, web, permission.Update)
+		ac.Deny(user, web, permission.Update)
+
+		decision := ac.Explain(user, web, permission.Update)
+		assert.False(t, decision.Allowed)
+		assert.False(t, decision.Owner)
+		assert.Equal(t, &permission.Rule{Entity: user, Resource: web, Permission: permission.Update, Allow: false}, decision.Rule)
+		assert.Same(t, user, decision.Entity)
+		assert.Equal(t, "denied: deny UPDATE for user on web", decision.String())
+	})
+
+	t.Run("Inherited from ancestors", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comment := web.CreateSub("comments").CreateSub("comment1")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		group.AddPermAll(web, true)
+
+		decision := ac.Explain(user, comment, permission.Update)
+		assert.True(t, decision.Allowed)
+		assert.Same(t, group, decision.Entity)
+		assert.Same(t, web, decision.Resource)
+		assert.Equal(t, permission.All, decision.Rule.Permission)
+		assert.Equal(t, "allowed: allow ALL for group on web", decision.String())
+		assert.Len(t, decision.Trace, 1)
+	})
+
+	t.Run("Missing grant", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		user := ac.CreateEntity("user")
+		ac.Allow(user, web, permission.Read)
+
+		decision := ac.Explain(user, web, permission.Delete)
+		assert.False(t, decision.Allowed)
+		assert.Nil(t, decision.Rule)
+		assert.Empty(t, decision.Trace)
+		assert.Same(t, user, decision.Entity)
+		assert.Same(t, web, decision.Resource)
+		assert.Equal(t, "denied: no matching rule", decision.String())
+	})
+
+	t.Run("Ownership", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comment := web.CreateSub("comment")
+		user := ac.CreateEntity("user")
+		web.AddOwners(user)
+		ac.Deny(user, comment, permission.Delete)
+
+		decision := ac.Explain(user, comment, permission.Read)
+		assert.True(t, decision.Allowed)
+		assert.True(t, decision.Owner)
+		assert.Nil(t, decision.Rule)
+		assert.Same(t, web, decision.Resource)
+		assert.Equal(t, "allowed: user owns web", decision.String())
+	})
+}
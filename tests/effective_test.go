@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectivePermissions(t *testing.T) {
+
+	t.Run("Sources", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		docs := ac.CreateResource("docs")
+		readme := docs.CreateSub("readme")
+		drafts := docs.CreateSub("drafts")
+		ac.CreateResource("billing")
+
+		ac.Allow(group, docs, permission.Read)
+		ac.Allow(user, readme, permission.Update)
+		ac.Deny(user, drafts, permission.Read)
+		ac.AllowPattern(user, "docs/*", "vote")
+
+		effective := ac.EffectivePermissions(user)
+		assert.Len(t, effective, 3)
+
+		assert.Equal(t, docs, effective[0].Resource)
+		assert.Equal(t, []permission.Permission{permission.Read}, effective[0].Permissions())
+		assert.True(t, effective[0].Grants[0].Inherited)
+		assert.False(t, effective[0].Grants[0].FromAncestor)
+		assert.Equal(t, group, effective[0].Grants[0].Decision.Entity)
+		assert.Equal(t, "READ (rule inherited from group)", effective[0].Grants[0].String())
+
+		assert.Equal(t, drafts, effective[1].Resource)
+		assert.Equal(t, []permission.Permission{"vote"}, effective[1].Permissions())
+
+		assert.Equal(t, readme, effective[2].Resource)
+		assert.Equal(t, []permission.Permission{permission.Read, permission.Update, "vote"}, effective[2].Permissions())
+		assert.Equal(t, "READ (rule inherited from group on docs)", effective[2].Grants[0].String())
+		assert.Equal(t, "UPDATE (rule)", effective[2].Grants[1].String())
+		assert.Equal(t, "docs/*", effective[2].Grants[2].Decision.Rule.Pattern)
+	})
+
+	t.Run("Ownership and roles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user := ac.CreateEntity("user")
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		billing := ac.CreateResource("billing")
+
+		comments.AddOwners(user)
+		ac.AssignRole(user, ac.CreateRole("viewer", permission.Read), billing)
+
+		effective := ac.EffectivePermissions(user)
+		assert.Len(t, effective, 2)
+		assert.Equal(t, billing, effective[0].Resource)
+		assert.Equal(t, "READ (role viewer)", effective[0].Grants[0].String())
+		assert.Equal(t, comments, effective[1].Resource)
+		assert.Equal(t, []permission.Permission{permission.Create, permission.Read, permission.Update, permission.Delete}, effective[1].Permissions())
+		assert.True(t, effective[1].Grants[0].Decision.Owner)
+		assert.Equal(t, "CREATE (ownership)", effective[1].Grants[0].String())
+	})
+
+	t.Run("Resource types and implications", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineResourceType("billing", []permission.Permission{"VIEW", "PAY"}, nil)
+		ac.DefineSet("billing-admin", "VIEW", "PAY")
+		user := ac.CreateEntity("user")
+		account := ac.CreateResource("account")
+		account.Type = "billing"
+
+		ac.Allow(user, account, "billing-admin")
+		effective := ac.EffectivePermissions(user)
+		assert.Len(t, effective, 1)
+		assert.Equal(t, []permission.Permission{"VIEW", "PAY", "billing-admin"}, effective[0].Permissions())
+	})
+}
@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpressions(t *testing.T) {
+	ctx := context.Background()
+
+	ac := permission.NewAccessControl()
+	owner := ac.CreateEntity("alice")
+	editors := ac.CreateEntity("editors")
+	bob := editors.CreateChild("bob")
+	web := ac.CreateResource("web")
+	comment := web.CreateSub("comment1")
+	comment.AddOwners(owner)
+
+	request := &permission.Request{
+		Subject:    bob,
+		Resource:   comment,
+		Permission: permission.Update,
+		Attributes: map[string]any{
+			"status": "draft",
+			"level":  3,
+			"tags":   []string{"news", "sport"},
+			"client": map[string]any{"country": "CZ"},
+		},
+		Time: time.Date(2026, time.March, 4, 10, 30, 0, 0, time.UTC),
+	}
+
+	t.Run("Evaluate", func(t *testing.T) {
+		cases := map[string]bool{
+			`true`:                                             true,
+			`subject.id == "bob"`:                              true,
+			`"editors" in subject.parents`:                     true,
+			`subject.id in resource.owners`:                    false,
+			`"alice" in resource.owners`:                       true,
+			`resource.path == "web/comment1"`:                  true,
+			`resource.parent == "web" && resource.id != "web"`: true,
+			`permission == "UPDATE"`:                           true,
+			`request.status == "draft"`:                        true,
+			`request.level >= 2 && request.level < 4`:          true,
+			`request.level * 2 + 1 == 7`:                       true,
+			`request.level % 2 == 1`:                           true,
+			`"sport" in request.tags`:                          true,
+			`request.client.country in ["CZ", "SK"]`:           true,
+			`request.missing == "x"`:                           false,
+			`!(request.status == "draft") || false`:            false,
+			`time.hour < 18 && time.weekday == "Wednesday"`:    true,
+			`time.year == 2026 && time.month == 3 && time.day == 4 && time.minute == 30`: true,
+			`lower("DrAft") == request.status`:                                           true,
+			`upper(subject.id) == "BOB"`:                                                 true,
+			`len(request.tags) == 2 && len("abc") == 3`:                                  true,
+			`startsWith(resource.path, "web/") && endsWith(resource.id, "1")`:            true,
+			`contains(request.tags, "news") && contains("draft", "raf")`:                 true,
+			`"raf" in 'draft'`:    true,
+			`"a" < "b" && -1 < 0`: true,
+		}
+
+		for source, expected := range cases {
+			expression, err := permission.Compile(source)
+			if !assert.NoError(t, err, source) {
+				continue
+			}
+			result, err := expression.Evaluate(ctx, request)
+			assert.NoError(t, err, source)
+			assert.Equal(t, expected, result, source)
+			assert.Equal(t, source, expression.String())
+		}
+	})
+
+	t.Run("Compile errors", func(t *testing.T) {
+		cases := map[string]int{
+			`subject.id ==`:          13,
+			`time.second == 1`:       0,
+			`subject.id.x == "x"`:    0,
+			`user.id == "x"`:         0,
+			`subject.id == 1`:        11,
+			`time.hour < "noon"`:     10,
+			`request.a && 1`:         10,
+			`lower(1) == "x"`:        6,
+			`upper("a", "b") == "A"`: 0,
+			`unknown("a")`:           0,
+			`1 + 2`:                  2,
+			`"a" in 1`:               4,
+			`request.a == "unclosed`: 13,
+			`request.a # 1`:          10,
+			`(true`:                  5,
+			`a == b == c`:            0,
+			`permission.x == "READ"`: 0,
+			`request == 1`:           0,
+			`1 == 1 ==`:              7,
+		}
+
+		for source, position := range cases {
+			_, err := permission.Compile(source)
+			var expressionErr *permission.ExpressionError
+			if !assert.ErrorAs(t, err, &expressionErr, source) {
+				continue
+			}
+			assert.ErrorIs(t, err, permission.ErrExpression)
+			assert.Equal(t, source, expressionErr.Expression)
+			if source != `a == b == c` {
+				assert.Equal(t, position, expressionErr.Position, source)
+			}
+		}
+
+		assert.Panics(t, func() { permission.MustCompile(`subject.id ==`) })
+
+		for source, message := range map[string]string{
+			`1 = 2`:                    `unexpected character '='`,
+			`request.a & request.b`:    `unexpected character '&'`,
+			`request.a | request.b`:    `unexpected character '|'`,
+			`request.a =request.b`:     `unexpected character '='`,
+			`request.a && request.b &`: `unexpected character '&'`,
+		} {
+			_, err := permission.Compile(source)
+			if assert.Error(t, err, source) {
+				assert.Contains(t, err.Error(), message, source)
+			}
+		}
+	})
+
+	t.Run("Runtime errors", func(t *testing.T) {
+		for _, source := range []string{
+			`request.status > 1`,
+			`request.level / 0 == 1`,
+			`lower(request.level) == "3"`,
+			`request.level`,
+		} {
+			result, err := permission.MustCompile(source).Evaluate(ctx, request)
+			assert.False(t, result, source)
+			assert.ErrorIs(t, err, permission.ErrCondition, source)
+		}
+	})
+
+	t.Run("Condition of a rule", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		articles := ac.CreateResource("articles")
+		article := articles.CreateSub("article1")
+		authors := ac.CreateEntity("authors")
+		alice := authors.CreateChild("alice")
+		article.AddOwners(ac.CreateEntity("bob"))
+
+		ac.AllowIf(authors, articles, permission.Update, permission.MustCompile(`request.status == "draft" && time.hour < 18`))
+		ac.DenyIf(authors, article, permission.Read, permission.MustCompile(`request.level > 2`))
+		ac.Allow(authors, articles, permission.Read)
+
+		assert.True(t, ac.CanWithContext(ctx, alice, article, permission.Update, map[string]any{"status": "draft"}) == (time.Now().Hour() < 18))
+		assert.False(t, ac.CanWithContext(ctx, alice, article, permission.Update, map[string]any{"status": "published"}))
+		assert.True(t, ac.CanWithContext(ctx, alice, article, permission.Read, map[string]any{"level": 1}))
+		assert.False(t, ac.CanWithContext(ctx, alice, article, permission.Read, map[string]any{"level": 5}))
+
+		decision := ac.ExplainWithContext(ctx, alice, article, permission.Read, map[string]any{"level": "high"})
+		assert.False(t, decision.Allowed, "failing deny condition applies")
+		assert.ErrorIs(t, decision.Err, permission.ErrCondition)
+	})
+
+	t.Run("Policy rules", func(t *testing.T) {
+		policy := `
+resources:
+  - id: web
+    resources:
+      - id: comments
+entities:
+  - id: editors
+    allow:
+      READ: [web]
+    rules:
+      - allow: UPDATE
+        resource: web/comments
+        if: request.status == "draft"
+      - deny: READ
+        resource: web/*
+        if: request.blocked == true
+`
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(policy))
+		if !assert.NoError(t, err) {
+			return
+		}
+		editors := ac.MustGetEntity("editors")
+		comments := ac.MustGetResource("web/comments")
+		assert.True(t, ac.CanWithContext(ctx, editors, comments, permission.Update, map[string]any{"status": "draft"}))
+		assert.False(t, ac.CanWithContext(ctx, editors, comments, permission.Update, nil))
+		assert.True(t, ac.CanRead(editors, comments))
+		assert.False(t, ac.CanWithContext(ctx, editors, comments, permission.Read, map[string]any{"blocked": true}))
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"rules":[{"deny":"READ","resource":"web/*","if":"request.blocked == true"},{"allow":"UPDATE","resource":"web/comments","if":"request.status == \"draft\""}]`)
+
+		restored := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, restored))
+		assert.True(t, restored.CanWithContext(ctx, restored.MustGetEntity("editors"), restored.MustGetResource("web/comments"), permission.Update, map[string]any{"status": "draft"}))
+	})
+
+	t.Run("Policy rule errors", func(t *testing.T) {
+		cases := map[string]struct {
+			policy string
+			line   int
+			err    error
+		}{
+			"type error": {
+				policy: "resources:\n  - id: web\nentities:\n  - id: alice\n    rules:\n      - allow: READ\n        resource: web\n        if: time.hour < \"noon\"\n",
+				line:   6,
+				err:    permission.ErrExpression,
+			},
+			"unknown resource": {
+				policy: "entities:\n  - id: alice\n    rules:\n      - allow: READ\n        resource: web\n        if: true\n",
+				line:   4,
+				err:    permission.ErrResourceNotFound,
+			},
+			"unknown permission": {
+				policy: "permissions: [vote]\nresources:\n  - id: web\nentities:\n  - id: alice\n    rules:\n      - deny: like\n        resource: web\n",
+				line:   7,
+				err:    permission.ErrUnknownPermission,
+			},
+		}
+
+		for name, c := range cases {
+			err := permission.ValidatePolicyYAML(strings.NewReader(c.policy))
+			var policyErr *permission.PolicyError
+			if assert.True(t, errors.As(err, &policyErr), name) {
+				assert.Equal(t, c.line, policyErr.Line, name)
+			}
+			assert.ErrorIs(t, err, c.err, name)
+		}
+
+		err := permission.ValidatePolicyYAML(strings.NewReader("resources:\n  - id: web\nentities:\n  - id: alice\n    rules:\n      - allow: READ\n        deny: READ\n        resource: web\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Go conditions cannot be serialized", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		user := ac.CreateEntity("user")
+		ac.AllowIf(user, web, permission.Read, permission.MustCompile(`true`), permission.RequestAttrEquals("a", 1))
+
+		_, err := json.Marshal(ac)
+		assert.ErrorIs(t, err, permission.ErrUnserializableCondition)
+	})
+}
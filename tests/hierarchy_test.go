@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHierarchyCycles(t *testing.T) {
+
+	t.Run("Entity cycle is rejected", func(t *testing.T) {
+		a := permission.NewEntity("a")
+		b := a.CreateChild("b")
+		c := b.CreateChild("c")
+
+		err := a.AddParents(c)
+		assert.ErrorIs(t, err, permission.ErrCycle)
+
+		var cycleErr *permission.CycleError
+		assert.ErrorAs(t, err, &cycleErr)
+		assert.Equal(t, "c", cycleErr.Parent)
+		assert.Equal(t, "a", cycleErr.Child)
+
+		assert.ErrorIs(t, c.AddChildren(a), permission.ErrCycle)
+		assert.ErrorIs(t, a.AddChildren(a), permission.ErrCycle)
+		assert.Empty(t, a.GetParents())
+		assert.Empty(t, c.GetChildren())
+
+		assert.NoError(t, a.AddChildren(c))
+		assert.Len(t, c.GetParents(), 2)
+	})
+
+	t.Run("Resource cycle is rejected", func(t *testing.T) {
+		web := permission.NewResource("web")
+		comments := web.CreateSub("comments")
+
+		assert.ErrorIs(t, comments.AttachSubs(web), permission.ErrCycle)
+		assert.ErrorIs(t, web.AttachSubs(web), permission.ErrCycle)
+		assert.Panics(t, func() { comments.AddSubs(web) })
+		assert.Nil(t, web.GetParent())
+	})
+
+	t.Run("Hand assembled cycles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+
+		a := ac.CreateEntity("a")
+		b := ac.CreateEntity("b")
+		a.Parents = append(a.Parents, b)
+		b.Parents = append(b.Parents, a)
+
+		web := ac.CreateResource("web")
+		comments := permission.NewResource("comments")
+		comments.Parent = web
+		web.Parent = comments
+
+		assert.False(t, ac.CanRead(a, comments))
+
+		b.Allow(web, permission.Read)
+		assert.True(t, ac.CanRead(a, comments))
+		assert.False(t, ac.CanUpdate(b, web))
+	})
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+const manage permission.Permission = "MANAGE"
+
+func TestImplications(t *testing.T) {
+
+	t.Run("Implied permissions", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.Imply(manage, permission.Update, permission.Delete)
+		ac.Imply(permission.Update, permission.Read)
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+
+		ac.Allow(user, doc, permission.Update)
+		assert.True(t, ac.HasPermission(user, doc, permission.Read))
+		assert.False(t, ac.CanDelete(user, doc))
+		assert.Equal(t, "allowed: allow UPDATE for user on doc", ac.Explain(user, doc, permission.Read).String())
+
+		admin := ac.CreateEntity("admin")
+		ac.Allow(admin, doc, manage)
+		assert.True(t, ac.CanRead(admin, doc), "transitive")
+		assert.True(t, ac.CanDelete(admin, doc))
+		assert.False(t, ac.CanCreate(admin, doc))
+	})
+
+	t.Run("Implies and Expand", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.Imply(manage, permission.Update, permission.Delete)
+		ac.Imply(permission.Update, permission.Read)
+
+		assert.True(t, ac.Implies(manage, permission.Read))
+		assert.True(t, ac.Implies(permission.Read, permission.Read))
+		assert.True(t, ac.Implies(permission.All, manage))
+		assert.False(t, ac.Implies(permission.Read, permission.Update))
+		assert.Equal(t, []permission.Permission{manage, permission.Update, permission.Delete, permission.Read}, ac.Expand(manage))
+		assert.ElementsMatch(t, []permission.Permission{permission.Create, permission.Read, permission.Update, permission.Delete, permission.All, manage}, ac.Expand(permission.All))
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.Imply("a", "b")
+		ac.Imply("b", "a")
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+
+		ac.Allow(user, doc, "b")
+		assert.True(t, ac.Can(user, doc, "a"))
+		assert.Equal(t, []permission.Permission{"a", "b"}, ac.Expand("a"))
+	})
+
+	t.Run("Denied implying permission", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.Imply(permission.Update, permission.Read)
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		doc := ac.CreateResource("doc")
+
+		ac.Allow(group, doc, permission.Read)
+		ac.Deny(user, doc, permission.Update)
+		assert.True(t, ac.CanRead(user, doc), "implications carry only allows")
+		assert.False(t, ac.CanUpdate(user, doc))
+		assert.Equal(t, "allowed: allow READ for group on doc", ac.Explain(user, doc, permission.Read).String())
+
+		ac.SetStrategy(permission.DenyOverrides)
+		assert.True(t, ac.CanRead(user, doc), "deny of an implying permission is no deny")
+		ac.SetStrategy(permission.FirstApplicable)
+		assert.True(t, ac.CanRead(user, doc))
+
+		ac.SetStrategy(permission.MostSpecificWins)
+		ac.Deny(group, doc, permission.Read)
+		ac.Allow(user, doc, permission.Update)
+		assert.True(t, ac.CanRead(user, doc), "allow of an implying permission of a nearer entity")
+	})
+
+	t.Run("Read but not edit", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.Imply(permission.Update, permission.Read)
+		user := ac.CreateEntity("user")
+		docs := ac.CreateResource("docs")
+		readme := docs.CreateSub("readme")
+
+		ac.Allow(user, readme, permission.Read)
+		ac.Deny(user, readme, permission.Update)
+		assert.True(t, ac.CanRead(user, readme))
+		assert.False(t, ac.CanUpdate(user, readme))
+
+		ac.Deny(user, readme, permission.All)
+		assert.False(t, ac.CanRead(user, readme), "deny of All still bans")
+
+		role := ac.CreateRole("reader", permission.Read)
+		other := ac.CreateEntity("other")
+		ac.AssignRole(other, role, nil)
+		ac.Deny(other, docs, permission.Update)
+		assert.True(t, ac.CanRead(other, docs), "role rule for the checked permission")
+		assert.True(t, ac.CanRead(other, readme))
+	})
+
+	t.Run("Permission sets", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineSet("editing", permission.Read, permission.Update)
+		user := ac.CreateEntity("user")
+		docs := ac.CreateResource("docs")
+
+		ac.Allow(user, docs, "editing")
+		assert.True(t, ac.CanUpdate(user, docs.CreateSub("readme")))
+		assert.False(t, ac.CanDelete(user, docs))
+		assert.Equal(t, map[permission.Permission][]permission.Permission{"editing": {permission.Read, permission.Update}}, ac.Sets())
+
+		ac.Deny(user, docs, permission.All)
+		assert.False(t, ac.CanRead(user, docs), "deny of All still bans")
+	})
+
+	t.Run("Roles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.Imply(manage, permission.Update, permission.Delete)
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+
+		ac.AssignRole(user, ac.CreateRole("manager", manage), nil)
+		assert.True(t, ac.CanDelete(user, doc))
+		assert.False(t, ac.CanRead(user, doc))
+	})
+
+	t.Run("Serialization", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.Imply(manage, permission.Update)
+		ac.DefineSet("editing", permission.Read, permission.Update)
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+		ac.Allow(user, doc, "editing")
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"implies":{"MANAGE":["UPDATE"]}`)
+		assert.Contains(t, string(data), `"sets":{"editing":["READ","UPDATE"]}`)
+
+		loaded := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, loaded))
+		loadedUser, _ := loaded.GetEntity("user")
+		loadedDoc, _ := loaded.GetResource("doc")
+		assert.True(t, loaded.CanUpdate(loadedUser, loadedDoc))
+		assert.Equal(t, ac.Sets(), loaded.Sets())
+		assert.True(t, loaded.Implies(manage, permission.Update))
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(`
+permissions: [MANAGE]
+implies:
+  MANAGE: [UPDATE, DELETE]
+sets:
+  editing: [READ, UPDATE]
+resources:
+  - id: doc
+entities:
+  - id: user
+    allow:
+      editing: [doc]
+  - id: admin
+    allow:
+      MANAGE: [doc]
+`))
+		assert.NoError(t, err)
+		user, _ := ac.GetEntity("user")
+		admin, _ := ac.GetEntity("admin")
+		doc, _ := ac.GetResource("doc")
+		assert.True(t, ac.CanRead(user, doc))
+		assert.True(t, ac.CanDelete(admin, doc))
+
+		_, err = permission.LoadPolicyYAML(strings.NewReader(`
+permissions: [MANAGE]
+implies:
+  MANAGE: [vote]
+`))
+		assert.True(t, errors.Is(err, permission.ErrUnknownPermission))
+	})
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+
+	t.Run("Round trip", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrategy(permission.DenyOverrides))
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		comment := comments.CreateSub("comment1")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		owner := permission.NewEntity("owner")
+		comment.AddOwners(owner)
+		ac.Allow(group, web, permission.Read)
+		ac.Deny(user, comments, permission.Read)
+		user.Allow(comment, permission.Update, "vote")
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"strategy": "deny-overrides",
+			"resources": [
+				{"id": "web", "resources": [
+					{"id": "comments", "resources": [
+						{"id": "comment1", "owners": ["owner"]}
+					]}
+				]}
+			],
+			"entities": [
+				{"id": "group", "allow": {"READ": ["web"]}},
+				{"id": "owner"},
+				{"id": "user", "parents": ["group"], "allow": {"UPDATE": ["web/comments/comment1"], "vote": ["web/comments/comment1"]}, "deny": {"READ": ["web/comments"]}}
+			]
+		}`, string(data))
+
+		loaded := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, loaded))
+		assert.Equal(t, permission.DenyOverrides, loaded.Strategy())
+
+		loadedUser := loaded.MustGetEntity("user")
+		loadedComment := loaded.MustGetResource("web/comments/comment1")
+		assert.False(t, loaded.CanRead(loadedUser, loadedComment))
+		assert.True(t, loaded.CanUpdate(loadedUser, loadedComment))
+		assert.True(t, loaded.Can(loadedUser, loadedComment, "vote"))
+		assert.True(t, loaded.CanRead(loaded.MustGetEntity("group"), loadedComment))
+		assert.True(t, loaded.CanDelete(loaded.MustGetEntity("owner"), loadedComment))
+
+		again, err := json.Marshal(loaded)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(data), string(again))
+	})
+
+	t.Run("Invalid documents", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+
+		err := json.Unmarshal([]byte(`{"entities":[{"id":"a"},{"id":"a"}]}`), ac)
+		assert.ErrorIs(t, err, permission.ErrDuplicateEntity)
+
+		err = json.Unmarshal([]byte(`{"resources":[{"id":"web"},{"id":"web"}]}`), ac)
+		assert.ErrorIs(t, err, permission.ErrDuplicateResource)
+
+		err = json.Unmarshal([]byte(`{"entities":[{"id":"a","parents":["missing"]}]}`), ac)
+		assert.ErrorIs(t, err, permission.ErrEntityNotFound)
+
+		err = json.Unmarshal([]byte(`{"entities":[{"id":"a","allow":{"READ":["missing"]}}]}`), ac)
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+
+		err = json.Unmarshal([]byte(`{"entities":[{"id":"a","parents":["b"]},{"id":"b","parents":["a"]}]}`), ac)
+		assert.ErrorIs(t, err, permission.ErrCycle)
+
+		err = json.Unmarshal([]byte(`{"resources":[{"id":"web","tenant":"acme","resources":[{"id":"x","tenant":"globex"}]}]}`), ac)
+		assert.ErrorIs(t, err, permission.ErrCrossTenant)
+
+		err = json.Unmarshal([]byte(`{"strategy":"random"}`), ac)
+		assert.ErrorIs(t, err, permission.ErrUnknownStrategy)
+	})
+
+	t.Run("Duplicate IDs cannot be encoded", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		user := ac.CreateEntity("user")
+		user.CreateChild("user")
+		ac.Allow(user, web, permission.Read)
+
+		_, err := json.Marshal(ac)
+		assert.ErrorIs(t, err, permission.ErrDuplicateEntity)
+	})
+
+	t.Run("Hand assembled cycles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		comments.SubResources["web"] = web
+
+		data, err := json.Marshal(ac)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Contains(t, string(data), `"resources":[{"id":"web","resources":[{"id":"comments"}]}]`)
+	})
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func paths(resources []*permission.Resource) []string {
+	result := []string{}
+	for _, resource := range resources {
+		result = append(result, resource.Path())
+	}
+	return result
+}
+
+func TestListResources(t *testing.T) {
+
+	t.Run("Grants and inheritance", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		docs := ac.CreateResource("docs")
+		docs.CreateSubs("readme", "changelog")
+		secret := docs.CreateSub("secret")
+		ac.CreateResource("billing")
+
+		ac.Allow(group, docs, permission.Read)
+		ac.Deny(user, secret, permission.Read)
+
+		assert.Equal(t, []string{"docs", "docs/changelog", "docs/readme"}, paths(ac.ListResources(user, permission.Read, permission.ListResourcesOptions{})))
+		assert.Equal(t, []string{}, paths(ac.ListResources(user, permission.Update, permission.ListResourcesOptions{})))
+	})
+
+	t.Run("Ownership, patterns and roles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user := ac.CreateEntity("user")
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		comments.CreateSubs("c1", "c2")
+		reports := ac.CreateResource("reports")
+		reports.CreateSubs("2025-q4", "2026-q1")
+		billing := ac.CreateResource("billing")
+		billing.CreateSub("invoices")
+
+		comments.AddOwners(user)
+		ac.AllowPattern(user, "reports/2026-*", permission.Update)
+		ac.AssignRole(user, ac.CreateRole("editor", permission.Update), billing)
+
+		assert.Equal(t, []string{"billing", "billing/invoices", "reports/2026-q1", "web/comments", "web/comments/c1", "web/comments/c2"},
+			paths(ac.ListResources(user, permission.Update, permission.ListResourcesOptions{})))
+	})
+
+	t.Run("Implied permissions and unscoped roles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.Imply(permission.Update, permission.Read)
+		user := ac.CreateEntity("user")
+		other := ac.CreateEntity("other")
+		docs := ac.CreateResource("docs")
+		docs.CreateSub("readme")
+		ac.CreateResource("billing")
+
+		ac.Allow(user, docs, permission.Update)
+		assert.Equal(t, []string{"docs", "docs/readme"}, paths(ac.ListResources(user, permission.Read, permission.ListResourcesOptions{})))
+
+		ac.AssignRole(other, ac.CreateRole("viewer", permission.Read), nil)
+		assert.Equal(t, []string{"billing", "docs", "docs/readme"}, paths(ac.ListResources(other, permission.Read, permission.ListResourcesOptions{})))
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user := ac.CreateEntity("user")
+		docs := ac.CreateResource("docs")
+		guides := docs.CreateSub("guides")
+		guides.CreateSub("install").Type = "page"
+		docs.CreateSub("readme").Type = "page"
+
+		ac.Allow(user, docs, permission.Read)
+		assert.Equal(t, []string{"docs/guides", "docs/guides/install"}, paths(ac.ListResources(user, permission.Read, permission.ListResourcesOptions{Root: guides})))
+		assert.Equal(t, []string{"docs/guides/install", "docs/readme"}, paths(ac.ListResources(user, permission.Read, permission.ListResourcesOptions{Type: "page"})))
+		assert.Equal(t, []string{"docs/guides/install"}, paths(ac.ListResources(user, permission.Read, permission.ListResourcesOptions{Root: guides, Type: "page"})))
+	})
+
+	t.Run("Matches HasPermission", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrategy(permission.DenyOverrides))
+		admins := ac.CreateEntity("admins")
+		staff := ac.CreateEntity("staff")
+		user := ac.CreateEntity("user")
+		user.AddParents(admins, staff)
+		root := ac.CreateResource("root")
+		for _, id := range []string{"a", "b", "c"} {
+			root.CreateSub(id).CreateSubs("x", "y")
+		}
+
+		ac.Allow(admins, root, permission.Delete)
+		ac.DenyPattern(staff, "root/b/*", permission.Delete)
+		ac.Deny(user, root.GetSub("c"), permission.All)
+
+		var expected []string
+		for _, resource := range []*permission.Resource{root, root.GetSub("a"), root.GetSub("a").GetSub("x"), root.GetSub("a").GetSub("y"), root.GetSub("b"), root.GetSub("c")} {
+			if ac.CanDelete(user, resource) {
+				expected = append(expected, resource.Path())
+			}
+		}
+		assert.Equal(t, []string{"root", "root/a", "root/a/x", "root/a/y", "root/b"}, expected)
+		assert.Equal(t, expected, paths(ac.ListResources(user, permission.Delete, permission.ListResourcesOptions{})))
+	})
+}
+
+func ids(entities []*permission.Entity) []string {
+	result := []string{}
+	for _, entity := range entities {
+		result = append(result, entity.ID)
+	}
+	return result
+}
+
+func TestListEntities(t *testing.T) {
+
+	t.Run("Groups, All and ancestors", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		admins := ac.CreateEntity("admins")
+		alice := admins.CreateChild("alice")
+		bob := admins.CreateChild("bob")
+		carol := ac.CreateEntity("carol")
+		ac.CreateEntity("dave")
+		docs := ac.CreateResource("docs")
+		readme := docs.CreateSub("readme")
+
+		ac.Allow(admins, docs, permission.All)
+		ac.Deny(bob, readme, permission.Delete)
+		ac.Allow(carol, docs, permission.Delete)
+
+		assert.Equal(t, []string{"admins", "alice", "carol"}, ids(ac.ListEntities(readme, permission.Delete, permission.ListEntitiesOptions{})))
+		assert.Equal(t, []string{"alice", "carol"}, ids(ac.ListEntities(readme, permission.Delete, permission.ListEntitiesOptions{Leaves: true})))
+		assert.Equal(t, []string{"admins", "alice", "bob"}, ids(ac.ListEntities(readme, permission.Read, permission.ListEntitiesOptions{})))
+		assert.Contains(t, ac.ListEntities(readme, permission.Delete, permission.ListEntitiesOptions{}), alice)
+		assert.NotContains(t, ac.ListEntities(readme, permission.Delete, permission.ListEntitiesOptions{}), bob)
+	})
+
+	t.Run("Ownership, patterns and roles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		owners := ac.CreateEntity("owners")
+		owner := owners.CreateChild("owner")
+		moderator := ac.CreateEntity("moderator")
+		editor := ac.CreateEntity("editor")
+		ac.CreateEntity("guest")
+		web := ac.CreateResource("web")
+		comment := web.CreateSub("comments").CreateSub("c1")
+
+		web.AddOwners(owners)
+		ac.AllowPattern(moderator, "web/comments/*", permission.Delete)
+		ac.AssignRole(editor, ac.CreateRole("editor", permission.Delete), web)
+
+		assert.Equal(t, []string{"editor", "moderator", "owner", "owners"}, ids(ac.ListEntities(comment, permission.Delete, permission.ListEntitiesOptions{})))
+		assert.Equal(t, []string{"editor", "moderator", "owner"}, ids(ac.ListEntities(comment, permission.Delete, permission.ListEntitiesOptions{Leaves: true})))
+		assert.Equal(t, owner, ac.ListEntities(comment, permission.Delete, permission.ListEntitiesOptions{Leaves: true})[2])
+	})
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaths(t *testing.T) {
+
+	t.Run("Resource path", func(t *testing.T) {
+		web := permission.NewResource("web")
+		comments := web.CreateSub("comments")
+		comment := comments.CreateSub("comment1")
+
+		assert.Equal(t, "web", web.Path())
+		assert.Equal(t, "web/comments/comment1", comment.Path())
+		assert.Same(t, comment, web.GetSubPath("comments/comment1"))
+		assert.Same(t, web, web.GetSubPath(""))
+		assert.Nil(t, web.GetSubPath("comments/missing"))
+	})
+
+	t.Run("Resolve resource", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comment := web.CreateSub("comments").CreateSub("comment1")
+
+		resolved, err := ac.ResolveResource("/web//comments/comment1/")
+		assert.NoError(t, err)
+		assert.Same(t, comment, resolved)
+
+		_, err = ac.ResolveResource("web/news")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+	})
+
+	t.Run("Can path", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		comments.CreateSub("comment1")
+		user := ac.CreateEntity("user")
+		ac.Allow(user, web, permission.Read)
+		ac.Deny(user, comments, permission.Read)
+
+		assert.True(t, ac.CanPath(user, "web", permission.Read))
+		assert.False(t, ac.CanPath(user, "web/comments/comment1", permission.Read))
+		assert.False(t, ac.CanPath(user, "web/missing", permission.Read))
+
+		decision, err := ac.ExplainPath(user, "web/comments/comment1", permission.Read)
+		assert.NoError(t, err)
+		assert.Same(t, comments, decision.Resource)
+
+		_, err = ac.ExplainPath(user, "missing", permission.Read)
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+	})
+}
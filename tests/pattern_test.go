@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatterns(t *testing.T) {
+
+	t.Run("Match", func(t *testing.T) {
+		assert.True(t, permission.MatchPattern("web/comments/*", "web/comments/comment1"))
+		assert.False(t, permission.MatchPattern("web/comments/*", "web/comments"))
+		assert.False(t, permission.MatchPattern("web/comments/*", "web/comments/comment1/reply"))
+		assert.True(t, permission.MatchPattern("web/**", "web"))
+		assert.True(t, permission.MatchPattern("web/**", "web/comments/comment1/reply"))
+		assert.True(t, permission.MatchPattern("**/comment?", "web/comments/comment1"))
+		assert.True(t, permission.MatchPattern("reports/2026-*", "reports/2026-01"))
+		assert.False(t, permission.MatchPattern("reports/2026-*", "reports/2025-12"))
+		assert.False(t, permission.MatchPattern("reports/[", "reports/["))
+
+		assert.True(t, permission.IsPattern("web/**"))
+		assert.False(t, permission.IsPattern("web/comments"))
+		assert.NoError(t, permission.ValidatePattern("web/comments/*"))
+		assert.ErrorIs(t, permission.ValidatePattern("reports/2026-["), permission.ErrBadPattern)
+	})
+
+	t.Run("Grants apply to resources created later", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		ac.AllowPattern(group, "web/comments/*", permission.Read)
+
+		comment := comments.CreateSub("comment1")
+		assert.True(t, ac.CanRead(user, comment))
+		assert.False(t, ac.CanRead(user, comments))
+		assert.False(t, ac.CanUpdate(user, comment))
+
+		reply := comment.CreateSub("reply")
+		assert.True(t, ac.CanRead(user, reply), "inherited from the matching ancestor")
+
+		ac.RevokePattern(group, "web/comments/*", permission.Read)
+		assert.False(t, ac.CanRead(user, comment))
+	})
+
+	t.Run("Concrete deny beats wildcard allow", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		reports := ac.CreateResource("reports")
+		january := reports.CreateSub("2026-01")
+		february := reports.CreateSub("2026-02")
+		user := ac.CreateEntity("user")
+
+		user.AllowPattern("reports/2026-*", permission.Read, permission.Update)
+		user.Deny(february, permission.Update)
+
+		assert.True(t, ac.CanUpdate(user, january))
+		assert.False(t, ac.CanUpdate(user, february))
+		assert.True(t, ac.CanRead(user, february))
+
+		decision := ac.Explain(user, january, permission.Update)
+		assert.Equal(t, "reports/2026-*", decision.Rule.Pattern)
+		assert.Equal(t, "allowed: allow UPDATE for user on reports/2026-* (reports/2026-01)", decision.String())
+	})
+
+	t.Run("Wildcard deny beats inherited grant", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		private := web.CreateSub("private")
+		user := ac.CreateEntity("user")
+
+		user.Allow(web, permission.Read)
+		user.DenyPattern("web/priv*", permission.Read)
+
+		assert.True(t, ac.CanRead(user, web))
+		assert.False(t, ac.CanRead(user, private))
+	})
+
+	t.Run("Serialization", func(t *testing.T) {
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(`
+resources:
+  - id: web
+    resources:
+      - id: comments
+        resources:
+          - id: comment1
+entities:
+  - id: user
+    allow:
+      READ: ["web/**"]
+    deny:
+      READ: [web/comments/comment1]
+`))
+		assert.NoError(t, err)
+		user := ac.MustGetEntity("user")
+		assert.True(t, ac.CanRead(user, ac.MustGetResource("web/comments")))
+		assert.False(t, ac.CanRead(user, ac.MustGetResource("web/comments/comment1")))
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"allow":{"READ":["web/**"]}`)
+
+		err = permission.ValidatePolicyYAML(strings.NewReader("entities:\n  - id: user\n    allow:\n      READ: [\"web/[\"]\n"))
+		assert.ErrorIs(t, err, permission.ErrBadPattern)
+	})
+}
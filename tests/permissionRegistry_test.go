@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionRegistry(t *testing.T) {
+
+	t.Run("Declaration", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DeclarePermission("vote", "Vote in polls", "community")
+		ac.DeclarePermission("publish", "Publish articles", "content")
+		ac.DeclarePermission("comment", "", "community")
+
+		assert.Equal(t, []permission.PermissionInfo{
+			{Permission: "comment", Category: "community"},
+			{Permission: "vote", Description: "Vote in polls", Category: "community"},
+			{Permission: "publish", Description: "Publish articles", Category: "content"},
+		}, ac.Permissions())
+
+		assert.NoError(t, ac.ValidatePermission("vote"))
+		assert.NoError(t, ac.ValidatePermission(permission.Read))
+		assert.True(t, errors.Is(ac.ValidatePermission("REDA"), permission.ErrUnknownPermission))
+
+		ac.DefineSet("editing", permission.Read, permission.Update)
+		assert.NoError(t, ac.ValidatePermission("editing"))
+	})
+
+	t.Run("Lenient mode", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+
+		assert.False(t, ac.StrictPermissions())
+		ac.Allow(user, doc, "REDA")
+		assert.True(t, ac.Can(user, doc, "REDA"))
+		assert.NoError(t, ac.Explain(user, doc, "vote").Err)
+	})
+
+	t.Run("Strict mode", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrictPermissions())
+		ac.DeclarePermission("vote", "Vote in polls", "community")
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+
+		assert.True(t, ac.StrictPermissions())
+		assert.Panics(t, func() { ac.Allow(user, doc, "REDA") })
+		assert.Panics(t, func() { ac.Deny(user, doc, "REDA") })
+		assert.Panics(t, func() { ac.AllowPattern(user, "doc/*", "REDA") })
+		assert.True(t, errors.Is(ac.ValidateGrant(user, doc, "REDA"), permission.ErrUnknownPermission))
+		assert.NoError(t, ac.ValidateGrant(user, doc, "vote"))
+
+		ac.Allow(user, doc, "vote")
+		assert.True(t, ac.Can(user, doc, "vote"))
+
+		user.AddPerm("REDA", doc, true)
+		decision := ac.Explain(user, doc, "REDA")
+		assert.False(t, decision.Allowed)
+		assert.True(t, errors.Is(decision.Err, permission.ErrUnknownPermission))
+
+		ac.SetStrictPermissions(false)
+		assert.True(t, ac.Can(user, doc, "REDA"))
+	})
+
+	t.Run("Serialization", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrictPermissions())
+		ac.DeclarePermission("vote", "Vote in polls", "community")
+		ac.DeclarePermission("publish", "", "")
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+		ac.Allow(user, doc, "vote")
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"strict":true,"permissions":["publish",{"name":"vote","description":"Vote in polls","category":"community"}]`)
+
+		loaded := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, loaded))
+		assert.True(t, loaded.StrictPermissions())
+		assert.Equal(t, ac.Permissions(), loaded.Permissions())
+	})
+
+	t.Run("Undeclared permissions are declared on export", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DeclarePermission("vote", "", "")
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+		ac.Allow(user, doc, "share")
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"permissions":["share","vote"]`)
+		assert.NoError(t, json.Unmarshal(data, permission.NewAccessControl()))
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(`
+strict: true
+permissions:
+  - vote
+  - name: publish
+    description: Publish articles
+    category: content
+resources:
+  - id: news
+entities:
+  - id: editor
+    allow:
+      publish: [news]
+`))
+		assert.NoError(t, err)
+		editor, _ := ac.GetEntity("editor")
+		news, _ := ac.GetResource("news")
+		assert.True(t, ac.Can(editor, news, "publish"))
+		assert.Equal(t, []permission.PermissionInfo{
+			{Permission: "vote"},
+			{Permission: "publish", Description: "Publish articles", Category: "content"},
+		}, ac.Permissions())
+
+		_, err = permission.LoadPolicyYAML(strings.NewReader(`
+strict: true
+resources:
+  - id: news
+entities:
+  - id: editor
+    allow:
+      REDA: [news]
+`))
+		assert.True(t, errors.Is(err, permission.ErrUnknownPermission))
+	})
+}
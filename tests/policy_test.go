@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyYAML(t *testing.T) {
+
+	t.Run("Load policy", func(t *testing.T) {
+		policy := `
+strategy: deny-overrides
+permissions: [vote]
+resources:
+  - id: web
+    owners: [admin]
+    resources:
+      - id: comments
+        resources:
+          - id: comment1
+entities:
+  - id: admin
+  - id: editors
+    allow:
+      READ: [web]
+      UPDATE: [web/comments]
+  - id: alice
+    parents: [editors]
+    allow:
+      vote: [web/comments/comment1]
+    deny:
+      UPDATE: [web/comments/comment1]
+`
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(policy))
+		assert.NoError(t, err)
+		assert.Equal(t, permission.DenyOverrides, ac.Strategy())
+
+		alice := ac.MustGetEntity("alice")
+		comment := ac.MustGetResource("web/comments/comment1")
+		assert.True(t, ac.CanRead(alice, comment))
+		assert.False(t, ac.CanUpdate(alice, comment))
+		assert.True(t, ac.CanUpdate(alice, ac.MustGetResource("web/comments")))
+		assert.True(t, ac.Can(alice, comment, "vote"))
+		assert.True(t, ac.CanDelete(ac.MustGetEntity("admin"), comment))
+	})
+
+	t.Run("Validation errors", func(t *testing.T) {
+		cases := map[string]struct {
+			policy string
+			line   int
+			err    error
+		}{
+			"unknown parent": {
+				policy: "entities:\n  - id: alice\n  - id: bob\n    parents: [ghost]\n",
+				line:   3,
+				err:    permission.ErrEntityNotFound,
+			},
+			"unknown owner": {
+				policy: "resources:\n  - id: web\n    resources:\n      - id: news\n        owners: [ghost]\n",
+				line:   4,
+				err:    permission.ErrEntityNotFound,
+			},
+			"unknown resource": {
+				policy: "resources:\n  - id: web\nentities:\n  - id: alice\n    allow:\n      READ: [web/missing]\n",
+				line:   4,
+				err:    permission.ErrResourceNotFound,
+			},
+			"duplicate entity": {
+				policy: "entities:\n  - id: alice\n  - id: alice\n",
+				line:   3,
+				err:    permission.ErrDuplicateEntity,
+			},
+			"duplicate resource": {
+				policy: "resources:\n  - id: web\n    resources:\n      - id: news\n      - id: news\n",
+				line:   5,
+				err:    permission.ErrDuplicateResource,
+			},
+			"unknown permission": {
+				policy: "permissions: [vote]\nresources:\n  - id: web\nentities:\n  - id: alice\n    allow:\n      REDA: [web]\n",
+				line:   5,
+				err:    permission.ErrUnknownPermission,
+			},
+			"misspelled key": {
+				policy: "resources:\n  - id: web\nentities:\n  - id: alice\n    alow:\n      READ: [web]\n",
+				line:   5,
+				err:    permission.ErrUnknownField,
+			},
+			"misspelled rule key": {
+				policy: "resources:\n  - id: web\nentities:\n  - id: alice\n    rules:\n      - allow: READ\n        resource: web\n        schedule: {days: [Mon], form: \"09:00\"}\n",
+				line:   8,
+				err:    permission.ErrUnknownField,
+			},
+			"misspelled top-level key": {
+				policy: "resources:\n  - id: web\nentitys:\n  - id: alice\n",
+				line:   3,
+				err:    permission.ErrUnknownField,
+			},
+			"cross-tenant sub-resource": {
+				policy: "resources:\n  - id: web\n    tenant: acme\n    resources:\n      - id: x\n        tenant: globex\n",
+				line:   5,
+				err:    permission.ErrCrossTenant,
+			},
+			"cycle": {
+				policy: "entities:\n  - id: a\n    parents: [b]\n  - id: b\n    parents: [a]\n",
+				line:   4,
+				err:    permission.ErrCycle,
+			},
+		}
+
+		for name, c := range cases {
+			err := permission.ValidatePolicyYAML(strings.NewReader(c.policy))
+			assert.ErrorIs(t, err, c.err, name)
+
+			var policyErr *permission.PolicyError
+			if assert.ErrorAs(t, err, &policyErr, name) {
+				assert.Equal(t, c.line, policyErr.Line, name)
+			}
+		}
+	})
+
+	t.Run("Empty and malformed policy", func(t *testing.T) {
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(""))
+		assert.NoError(t, err)
+		assert.Empty(t, ac.Entities)
+
+		_, err = permission.LoadPolicyYAML(strings.NewReader("entities: {"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"encoding/json"
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+
+	t.Run("Get entity", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user := ac.CreateEntity("user1")
+
+		found, err := ac.GetEntity("user1")
+		assert.NoError(t, err)
+		assert.Same(t, user, found)
+		assert.Same(t, user, ac.MustGetEntity("user1"))
+
+		_, err = ac.GetEntity("missing")
+		assert.ErrorIs(t, err, permission.ErrEntityNotFound)
+		assert.Panics(t, func() { ac.MustGetEntity("missing") })
+	})
+
+	t.Run("Duplicate entity", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user := ac.CreateEntity("user1")
+
+		assert.NoError(t, ac.RegisterEntity(user))
+		assert.ErrorIs(t, ac.RegisterEntity(permission.NewEntity("user1")), permission.ErrDuplicateEntity)
+		assert.Panics(t, func() { ac.AddEntity(permission.NewEntity("user1")) })
+		assert.Panics(t, func() { ac.CreateEntity("user1") })
+		assert.Len(t, ac.Entities, 1)
+
+		err := ac.RegisterEntities(permission.NewEntity("user2"), permission.NewEntity("user2"))
+		assert.ErrorIs(t, err, permission.ErrDuplicateEntity)
+		assert.Len(t, ac.Entities, 2)
+	})
+
+	t.Run("Get resource by path", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comment := web.CreateSub("comments").CreateSub("comment1")
+
+		found, err := ac.GetResource("web")
+		assert.NoError(t, err)
+		assert.Same(t, web, found)
+		assert.Same(t, comment, ac.MustGetResource("web/comments/comment1"))
+
+		_, err = ac.GetResource("web/comments/missing")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+		assert.Panics(t, func() { ac.MustGetResource("missing") })
+	})
+
+	t.Run("Duplicate resource", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+
+		assert.NoError(t, ac.RegisterResources(web, comments))
+		assert.ErrorIs(t, ac.RegisterResource(permission.NewResource("web")), permission.ErrDuplicateResource)
+		assert.Panics(t, func() { ac.AddResources(permission.NewResource("web")) })
+		assert.Len(t, ac.Resources, 2)
+		assert.Same(t, comments, ac.MustGetResource("web/comments"))
+	})
+
+	t.Run("Moved resources", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comments := ac.CreateResource("comments")
+		comments.CreateSub("comment1")
+
+		web.AddSubs(comments)
+		_, err := ac.GetResource("comments")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+		assert.Same(t, comments, ac.MustGetResource("web/comments"))
+		assert.Equal(t, "web/comments/comment1", ac.MustGetResource("web/comments/comment1").Path())
+
+		root := permission.NewResource("comments")
+		assert.NoError(t, ac.RegisterResource(root))
+		assert.Same(t, root, ac.MustGetResource("comments"))
+
+		wiki := permission.NewResource("wiki")
+		wiki.AddSubs(web)
+		assert.Same(t, comments, ac.MustGetResource("wiki/web/comments"), "resolved through the new root")
+		_, err = ac.GetResource("web")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+
+		wiki.RemoveSubs(web)
+		assert.Same(t, web, ac.MustGetResource("web"))
+		assert.ErrorIs(t, ac.RegisterResource(permission.NewResource("web")), permission.ErrDuplicateResource)
+	})
+
+	t.Run("Detached and removed resources", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		docs := permission.NewResource("docs")
+		readme := docs.CreateSub("readme")
+		assert.NoError(t, ac.RegisterResource(readme))
+		assert.Same(t, readme, ac.MustGetResource("docs/readme"))
+
+		docs.RemoveSubs(readme)
+		assert.Same(t, readme, ac.MustGetResource("readme"))
+		_, err := ac.GetResource("docs")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound, "no registered resource is left in docs")
+
+		ac.RemoveResource(readme)
+		_, err = ac.GetResource("readme")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+		assert.NoError(t, ac.RegisterResource(permission.NewResource("readme")))
+	})
+
+	t.Run("Loaded resources", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal([]byte(`{"resources":[{"id":"web"},{"id":"comments"}]}`), ac))
+		web, comments := ac.MustGetResource("web"), ac.MustGetResource("comments")
+
+		web.AddSubs(comments)
+		assert.Same(t, comments, ac.MustGetResource("web/comments"))
+		_, err := ac.GetResource("comments")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+	})
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	share permission.Permission = "SHARE"
+	view  permission.Permission = "VIEW"
+	pay   permission.Permission = "PAY"
+)
+
+func TestResourceTypes(t *testing.T) {
+
+	t.Run("Grants", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineResourceType("document", []permission.Permission{permission.Read, permission.Update, share}, []string{"owner", "viewer"})
+		ac.DefineResourceType("billing", []permission.Permission{view, pay}, nil)
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+		doc.Type = "document"
+		account := ac.CreateResource("account")
+		account.Type = "billing"
+
+		ac.Allow(user, doc, share)
+		ac.Allow(user, account, pay)
+		ac.Allow(user, account, permission.All)
+		assert.True(t, ac.Can(user, doc, share))
+		assert.True(t, ac.Can(user, account, pay))
+
+		assert.Panics(t, func() { ac.Allow(user, account, permission.Read) })
+		err := ac.ValidateGrant(user, doc, pay)
+		assert.True(t, errors.Is(err, permission.ErrInapplicablePermission))
+		assert.Equal(t, "permission: permission not applicable to resource type: PAY on doc of type document", err.Error())
+	})
+
+	t.Run("Checks", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineResourceType("billing", []permission.Permission{view, pay}, nil)
+		user := ac.CreateEntity("user")
+		account := ac.CreateResource("account")
+		account.Type = "billing"
+
+		ac.Allow(user, account, permission.All)
+		assert.True(t, ac.Can(user, account, view))
+		decision := ac.Explain(user, account, permission.Delete)
+		assert.False(t, decision.Allowed)
+		assert.True(t, errors.Is(decision.Err, permission.ErrInapplicablePermission))
+	})
+
+	t.Run("Implied permissions", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineResourceType("billing", []permission.Permission{view, pay}, nil)
+		ac.DefineSet("billing-admin", view, pay)
+		user := ac.CreateEntity("user")
+		account := ac.CreateResource("account")
+		account.Type = "billing"
+
+		ac.Allow(user, account, "billing-admin")
+		assert.True(t, ac.Can(user, account, pay))
+	})
+
+	t.Run("Undefined types", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DeclarePermission("vote", "", "")
+		user := ac.CreateEntity("user")
+		doc := ac.CreateResource("doc")
+		doc.Type = "unknown"
+
+		ac.Allow(user, doc, "vote")
+		assert.True(t, ac.Can(user, doc, "vote"))
+		assert.Equal(t, []permission.Permission{permission.Create, permission.Read, permission.Update, permission.Delete, "vote"}, ac.PermissionsFor(doc))
+		assert.Nil(t, ac.RelationsFor(doc))
+
+		_, err := ac.GetResourceType("unknown")
+		assert.True(t, errors.Is(err, permission.ErrResourceTypeNotFound))
+	})
+
+	t.Run("Introspection", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineResourceType("document", []permission.Permission{permission.Read, permission.Update, share}, []string{"owner", "viewer"})
+		ac.DefineResourceType("billing", []permission.Permission{view, pay}, nil)
+		doc := ac.CreateResource("doc")
+		doc.Type = "document"
+
+		assert.Equal(t, []permission.Permission{permission.Read, permission.Update, share}, ac.PermissionsFor(doc))
+		assert.Equal(t, []string{"owner", "viewer"}, ac.RelationsFor(doc))
+		types := ac.ResourceTypes()
+		assert.Len(t, types, 2)
+		assert.Equal(t, "billing", types[0].Name)
+		assert.Equal(t, "document", types[1].Name)
+	})
+
+	t.Run("Serialization", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineResourceType("billing", []permission.Permission{view, pay}, []string{"payer"})
+		user := ac.CreateEntity("user")
+		account := ac.CreateResource("account")
+		account.Type = "billing"
+		ac.Allow(user, account, pay)
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"types":[{"name":"billing","permissions":["VIEW","PAY"],"relations":["payer"]}]`)
+		assert.Contains(t, string(data), `{"id":"account","type":"billing"}`)
+
+		loaded := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, loaded))
+		loadedAccount, _ := loaded.GetResource("account")
+		assert.Equal(t, "billing", loadedAccount.Type)
+		assert.Equal(t, ac.ResourceTypes(), loaded.ResourceTypes())
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		_, err := permission.LoadPolicyYAML(strings.NewReader(`
+types:
+  - name: billing
+    permissions: [VIEW, PAY]
+resources:
+  - id: account
+    type: billing
+entities:
+  - id: user
+    allow:
+      READ: [account]
+`))
+		assert.True(t, errors.Is(err, permission.ErrInapplicablePermission))
+		var policyErr *permission.PolicyError
+		assert.True(t, errors.As(err, &policyErr))
+		assert.Equal(t, 9, policyErr.Line)
+	})
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevocation(t *testing.T) {
+
+	t.Run("Revoke returns to inherited state", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		ac.Allow(group, web, permission.Read)
+		ac.Deny(user, web, permission.Read)
+		user.Allow(web, permission.Update, permission.Delete)
+
+		assert.False(t, ac.CanRead(user, web))
+		ac.Revoke(user, web, permission.Read)
+		assert.True(t, ac.CanRead(user, web))
+
+		user.Revoke(web, permission.Update)
+		assert.False(t, ac.CanUpdate(user, web))
+		assert.True(t, ac.CanDelete(user, web))
+
+		user.RevokeResource(web)
+		assert.False(t, ac.CanDelete(user, web))
+	})
+
+	t.Run("Remove hierarchy links", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		group := ac.CreateEntity("group")
+		team := ac.CreateEntity("team")
+		user := group.CreateChild("user")
+		team.AddChildren(user)
+		ac.Allow(group, web, permission.Read)
+		ac.Allow(team, web, permission.Update)
+
+		user.RemoveParents(group)
+		assert.False(t, ac.CanRead(user, web))
+		assert.Empty(t, group.GetChildren())
+
+		team.RemoveChildren(user)
+		assert.False(t, ac.CanUpdate(user, web))
+		assert.Empty(t, user.GetParents())
+	})
+
+	t.Run("Remove owners and subs", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		web.CreateSub("news")
+		user := ac.CreateEntity("user")
+		web.AddOwners(user)
+
+		assert.True(t, ac.CanDelete(user, comments))
+		web.RemoveOwners(user)
+		assert.False(t, ac.CanDelete(user, comments))
+		assert.Empty(t, web.GetOwners())
+
+		ac.Allow(user, web, permission.Read)
+		web.RemoveSub("comments")
+		assert.Nil(t, comments.GetParent())
+		assert.Nil(t, web.GetSub("comments"))
+		assert.False(t, ac.CanRead(user, comments))
+
+		news := web.GetSub("news")
+		web.RemoveSubs(news)
+		assert.Empty(t, web.GetSubs())
+	})
+
+	t.Run("Moving a sub-resource", func(t *testing.T) {
+		web := permission.NewResource("web")
+		archive := permission.NewResource("archive")
+		news := web.CreateSub("news")
+
+		archive.AddSubs(news)
+		assert.Nil(t, web.GetSub("news"))
+		assert.Same(t, archive, news.GetParent())
+	})
+
+	t.Run("Remove entity", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comment := web.CreateSub("comment")
+		group := ac.CreateEntity("group")
+		user := ac.CreateEntity("user")
+		member := ac.CreateEntity("member")
+		group.AddChildren(user)
+		user.AddChildren(member)
+		comment.AddOwners(user)
+
+		ac.RemoveEntity(user)
+
+		_, err := ac.GetEntity("user")
+		assert.ErrorIs(t, err, permission.ErrEntityNotFound)
+		assert.Len(t, ac.Entities, 2)
+		assert.Empty(t, group.GetChildren())
+		assert.Empty(t, member.GetParents())
+		assert.Empty(t, comment.GetOwners())
+		assert.NoError(t, ac.RegisterEntity(permission.NewEntity("user")))
+	})
+
+	t.Run("Remove resource", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		web := ac.CreateResource("web")
+		comments := web.CreateSub("comments")
+		comment := comments.CreateSub("comment1")
+		ac.AddResource(comment)
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		ac.Allow(user, comment, permission.Read)
+		ac.Allow(group, comments, permission.Update)
+
+		ac.RemoveResource(comments)
+
+		assert.Nil(t, web.GetSub("comments"))
+		_, err := ac.GetResource("web/comments/comment1")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+		assert.Len(t, ac.Resources, 1)
+		assert.Empty(t, user.Permission[permission.Read])
+		assert.Empty(t, group.Permission[permission.Update])
+	})
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoles(t *testing.T) {
+
+	t.Run("Role assignment", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		editor := ac.CreateRole("editor", permission.Read, permission.Update)
+		docs := ac.CreateResource("docs")
+		readme := docs.CreateSub("readme")
+		billing := ac.CreateResource("billing")
+		user := ac.CreateEntity("user1")
+
+		ac.AssignRole(user, editor, docs)
+		assert.True(t, ac.CanUpdate(user, readme))
+		assert.True(t, ac.CanRead(user, docs))
+		assert.False(t, ac.CanDelete(user, readme))
+		assert.False(t, ac.CanRead(user, billing), "outside of the scope")
+		assert.Equal(t, "allowed: allow UPDATE for user1 on docs by role editor", ac.Explain(user, readme, permission.Update).String())
+
+		ac.UnassignRole(user, editor, docs)
+		assert.False(t, ac.CanUpdate(user, readme))
+
+		ac.AssignRole(user, editor, nil)
+		assert.True(t, ac.CanRead(user, billing), "unscoped")
+		assert.Equal(t, []permission.RoleBinding{{Role: editor}}, user.GetRoles())
+	})
+
+	t.Run("Resource-bound grants", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		finance := ac.CreateResource("finance")
+		invoices := finance.CreateSub("invoices")
+		reports := finance.CreateSub("reports")
+		accountant := ac.CreateRole("accountant", permission.Read)
+		accountant.AllowOn(invoices, permission.Update)
+		user := ac.CreateEntity("user")
+
+		ac.AssignRole(user, accountant, nil)
+		assert.True(t, ac.CanRead(user, reports))
+		assert.True(t, ac.CanUpdate(user, invoices.CreateSub("2026-01")))
+		assert.False(t, ac.CanUpdate(user, reports))
+
+		other := ac.CreateEntity("other")
+		ac.AssignRole(other, accountant, reports)
+		assert.False(t, ac.CanUpdate(other, invoices), "grant outside of the scope")
+		assert.True(t, ac.CanRead(other, reports))
+		assert.False(t, ac.CanRead(other, invoices))
+
+		accountant.RevokeOn(invoices, permission.Update)
+		assert.False(t, ac.CanUpdate(user, invoices))
+		accountant.Revoke(permission.Read)
+		assert.False(t, ac.CanRead(user, reports))
+		assert.Empty(t, accountant.GetPermissions())
+		assert.Empty(t, accountant.GetGrants())
+	})
+
+	t.Run("Members inherit role assignments", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		editor := ac.CreateRole("editor", permission.Update)
+		docs := ac.CreateResource("docs")
+		group := ac.CreateEntity("group1")
+		user := group.CreateChild("user1")
+		ac.AssignRole(group, editor, docs)
+
+		assert.True(t, ac.CanUpdate(user, docs))
+		assert.Empty(t, user.GetRoles(), "membership and role assignment stay distinct")
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		editor := ac.CreateRole("editor", permission.Update, permission.Read)
+		docs := ac.CreateResource("docs")
+		secret := docs.CreateSub("secret")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+
+		ac.AssignRole(user, editor, docs)
+		ac.DenyPattern(user, "docs", permission.Update)
+		assert.False(t, ac.CanUpdate(user, docs), "pattern rule beats role at the same node")
+
+		ac.Deny(user, secret, permission.Read)
+		assert.False(t, ac.CanRead(user, secret), "nearer resource wins")
+		assert.True(t, ac.CanRead(user, docs))
+
+		ac.Deny(group, docs, permission.Read)
+		assert.True(t, ac.CanRead(user, docs), "role of the entity beats rule of the parent")
+	})
+
+	t.Run("Registry", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		editor := ac.CreateRole("editor")
+		role, err := ac.GetRole("editor")
+		assert.NoError(t, err)
+		assert.Same(t, editor, role)
+		_, err = ac.GetRole("ghost")
+		assert.ErrorIs(t, err, permission.ErrRoleNotFound)
+		assert.ErrorIs(t, ac.RegisterRole(permission.NewRole("editor")), permission.ErrDuplicateRole)
+		assert.Panics(t, func() { ac.CreateRole("editor") })
+
+		viewer := permission.NewRole("viewer", permission.Read)
+		ac.AssignRole(ac.CreateEntity("user"), viewer, nil)
+		assert.Equal(t, []*permission.Role{editor, viewer}, ac.Roles(), "assigned roles are registered")
+
+		acme := ac.CreateResourceInTenant("acme", "docs")
+		assert.Panics(t, func() { ac.AssignRole(ac.MustGetEntity("user"), viewer, acme) })
+	})
+
+	t.Run("Removing a resource", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		docs := ac.CreateResource("docs")
+		role := ac.CreateRole("role").AllowOn(docs, permission.Read)
+		user := ac.CreateEntity("user")
+		ac.AssignRole(user, role, docs)
+
+		ac.RemoveResource(docs)
+		assert.Empty(t, role.GetGrants())
+		assert.Empty(t, user.GetRoles())
+	})
+
+	t.Run("Serialization", func(t *testing.T) {
+		policy := `
+resources:
+  - id: docs
+  - id: finance
+    resources:
+      - id: invoices
+roles:
+  - id: editor
+    permissions: [READ, UPDATE]
+  - id: accountant
+    permissions: [READ]
+    grants:
+      UPDATE: [finance/invoices]
+entities:
+  - id: editors
+    roles:
+      - role: editor
+        scope: docs
+  - id: alice
+    parents: [editors]
+    roles:
+      - role: accountant
+`
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(policy))
+		if !assert.NoError(t, err) {
+			return
+		}
+		alice := ac.MustGetEntity("alice")
+		assert.True(t, ac.CanUpdate(alice, ac.MustGetResource("docs")))
+		assert.True(t, ac.CanUpdate(alice, ac.MustGetResource("finance/invoices")))
+		assert.False(t, ac.CanUpdate(alice, ac.MustGetResource("finance")))
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"roles":[{"id":"accountant","permissions":["READ"],"grants":{"UPDATE":["finance/invoices"]}},{"id":"editor","permissions":["READ","UPDATE"]}]`)
+		assert.Contains(t, string(data), `{"id":"editors","roles":[{"role":"editor","scope":"docs"}]}`)
+
+		restored := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, restored))
+		assert.True(t, restored.CanUpdate(restored.MustGetEntity("alice"), restored.MustGetResource("finance/invoices")))
+
+		err = permission.ValidatePolicyYAML(strings.NewReader("entities:\n  - id: alice\n    roles:\n      - role: ghost\n"))
+		assert.ErrorIs(t, err, permission.ErrRoleNotFound)
+		err = permission.ValidatePolicyYAML(strings.NewReader("permissions: [vote]\nroles:\n  - id: voter\n    permissions: [vot]\n"))
+		assert.ErrorIs(t, err, permission.ErrUnknownPermission)
+	})
+}
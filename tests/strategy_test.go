@@ -0,0 +1,239 @@
+package tests
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrategies(t *testing.T) {
+	setup := func(ac *permission.AccessControl) (user, allowGroup, denyGroup *permission.Entity, web, comment *permission.Resource) {
+		web = ac.CreateResource("web")
+		comment = web.CreateSub("comments").CreateSub("comment1")
+		allowGroup = ac.CreateEntity("allowGroup")
+		denyGroup = ac.CreateEntity("denyGroup")
+		user = ac.CreateEntity("user")
+		user.AddParents(denyGroup, allowGroup)
+
+		ac.Allow(allowGroup, web, permission.Read)
+		ac.Deny(denyGroup, web, permission.Read)
+		return
+	}
+
+	t.Run("Default is most specific wins", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		assert.Equal(t, permission.MostSpecificWins, ac.Strategy())
+		assert.Equal(t, "most-specific-wins", ac.Strategy().String())
+	})
+
+	t.Run("Most specific wins", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrategy(permission.MostSpecificWins))
+		user, allowGroup, denyGroup, web, comment := setup(ac)
+
+		assert.False(t, ac.CanRead(user, comment), "parents at the same distance disagree, deny wins")
+
+		ac.Allow(allowGroup, comment, permission.Read)
+		assert.True(t, ac.CanRead(user, comment), "nearer resource wins")
+
+		ac.Deny(user, web, permission.Read)
+		assert.False(t, ac.CanRead(user, comment), "own rule wins over parents")
+
+		ac.Allow(denyGroup, web, permission.Update)
+		ac.Deny(denyGroup, comment, permission.Update)
+		assert.False(t, ac.CanUpdate(user, comment))
+		assert.True(t, ac.CanUpdate(user, web))
+	})
+
+	t.Run("Deny overrides", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrategy(permission.DenyOverrides))
+		user, _, denyGroup, _, comment := setup(ac)
+
+		ac.Allow(user, comment, permission.Read)
+		assert.False(t, ac.CanRead(user, comment))
+
+		decision := ac.Explain(user, comment, permission.Read)
+		assert.Same(t, denyGroup, decision.Entity)
+		assert.Len(t, decision.Trace, 3)
+
+		ac.Allow(user, comment, permission.Update)
+		assert.True(t, ac.CanUpdate(user, comment))
+	})
+
+	t.Run("Allow overrides", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrategy(permission.AllowOverrides))
+		user, allowGroup, _, _, comment := setup(ac)
+
+		ac.Deny(user, comment, permission.Read)
+		assert.True(t, ac.CanRead(user, comment))
+		assert.Same(t, allowGroup, ac.Explain(user, comment, permission.Read).Entity)
+
+		ac.Deny(user, comment, permission.Update)
+		assert.False(t, ac.CanUpdate(user, comment))
+	})
+
+	t.Run("First applicable", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.SetStrategy(permission.FirstApplicable)
+		user, allowGroup, denyGroup, _, comment := setup(ac)
+
+		assert.False(t, ac.CanRead(user, comment), "denyGroup was added first")
+
+		other := ac.CreateEntity("other")
+		other.AddParents(allowGroup, denyGroup)
+		assert.True(t, ac.CanRead(other, comment), "allowGroup was added first")
+
+		ac.Allow(user, comment, permission.Read)
+		assert.True(t, ac.CanRead(user, comment))
+	})
+
+	t.Run("Any path allows", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrategy(permission.AnyPathAllows))
+		user, allowGroup, denyGroup, web, comment := setup(ac)
+
+		assert.True(t, ac.CanRead(user, comment), "parents disagree, allow wins")
+		assert.Same(t, allowGroup, ac.Explain(user, comment, permission.Read).Entity)
+
+		ac.Allow(denyGroup, web, permission.Update)
+		ac.Deny(user, web, permission.Update)
+		assert.False(t, ac.CanUpdate(user, web), "own deny stops the walk")
+		ac.Allow(allowGroup, comment, permission.Update)
+		assert.True(t, ac.CanUpdate(user, comment), "parent allows on a sub-resource")
+
+		ac.Deny(user, comment, permission.Delete)
+		assert.False(t, ac.CanDelete(user, comment))
+		assert.Equal(t, "denied: deny DELETE for user on web/comments/comment1", ac.Explain(user, comment, permission.Delete).String())
+
+		strategy := permission.MostSpecificWins
+		assert.NoError(t, strategy.UnmarshalText([]byte("any-path-allows")))
+		assert.Equal(t, permission.AnyPathAllows, strategy)
+	})
+
+	t.Run("Any path allows stops at a deny before ownership", func(t *testing.T) {
+		ac := permission.NewAccessControl(permission.WithStrategy(permission.AnyPathAllows))
+		user := ac.CreateEntity("user")
+		docs := ac.CreateResource("docs")
+		readme := docs.CreateSub("readme")
+		docs.AddOwners(user)
+		ac.Deny(user, readme, permission.Read)
+
+		assert.False(t, ac.CanRead(user, readme))
+		assert.True(t, ac.CanUpdate(user, readme))
+		assert.Equal(t, "allowed: user owns docs", ac.Explain(user, readme, permission.Update).String())
+
+		ac.SetStrategy(permission.MostSpecificWins)
+		assert.True(t, ac.CanRead(user, readme), "ownership wins under other strategies")
+	})
+
+	t.Run("Any path allows matches the walk without strategies", func(t *testing.T) {
+		random := rand.New(rand.NewSource(1))
+		for round := 0; round < 200; round++ {
+			ac := permission.NewAccessControl(permission.WithStrategy(permission.AnyPathAllows))
+			var entities []*permission.Entity
+			for i := 0; i < 6; i++ {
+				entity := ac.CreateEntity(fmt.Sprint("e", i))
+				for _, parent := range entities {
+					if random.Intn(3) == 0 {
+						assert.NoError(t, entity.AddParents(parent))
+					}
+				}
+				entities = append(entities, entity)
+			}
+			resources := []*permission.Resource{ac.CreateResource("r0")}
+			for i := 1; i < 6; i++ {
+				resources = append(resources, resources[random.Intn(i)].CreateSub(fmt.Sprint("r", i)))
+			}
+			for _, resource := range resources {
+				for _, entity := range entities {
+					switch random.Intn(8) {
+					case 0:
+						ac.Allow(entity, resource, permission.Read)
+					case 1:
+						ac.Deny(entity, resource, permission.Read)
+					case 2:
+						resource.AddOwners(entity)
+					}
+				}
+			}
+
+			for _, entity := range entities {
+				for _, resource := range resources {
+					assert.Equal(t, walkWithoutStrategies(entity, resource), ac.CanRead(entity, resource), "round %d: %s on %s", round, entity.ID, resource.Path())
+				}
+			}
+		}
+	})
+}
+
+// walkWithoutStrategies is the check of READ before strategies were introduced.
+func walkWithoutStrategies(entity *permission.Entity, resource *permission.Resource) bool {
+	for _, owner := range resource.Owners {
+		if owner == entity {
+			return true
+		}
+	}
+	if allowed, ok := entity.Permission[permission.Read][resource]; ok {
+		return allowed
+	}
+	for _, parent := range entity.Parents {
+		if walkWithoutStrategies(parent, resource) {
+			return true
+		}
+	}
+	return resource.Parent != nil && walkWithoutStrategies(entity, resource.Parent)
+}
+
+func TestDenyAll(t *testing.T) {
+	setup := func(ac *permission.AccessControl) (user, group *permission.Entity, web, comment *permission.Resource) {
+		web = ac.CreateResource("web")
+		comment = web.CreateSub("comment")
+		group = ac.CreateEntity("group")
+		user = group.CreateChild("user")
+		ac.AddEntity(user)
+
+		ac.Allow(group, web, permission.Read)
+		user.AddPermAll(web, false)
+		return
+	}
+
+	t.Run("Blocks grants of parents", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user, group, web, comment := setup(ac)
+
+		assert.False(t, ac.CanRead(user, web))
+		assert.False(t, ac.CanRead(user, comment))
+		assert.True(t, ac.CanRead(group, comment))
+
+		decision := ac.Explain(user, comment, permission.Read)
+		assert.Equal(t, permission.All, decision.Rule.Permission)
+		assert.Equal(t, "denied: deny ALL for user on web", decision.String())
+	})
+
+	t.Run("Wins over specific grant on the same resource", func(t *testing.T) {
+		for _, strategy := range []permission.Strategy{permission.MostSpecificWins, permission.DenyOverrides, permission.FirstApplicable, permission.AnyPathAllows} {
+			ac := permission.NewAccessControl(permission.WithStrategy(strategy))
+			user, _, web, _ := setup(ac)
+			ac.Allow(user, web, permission.Read)
+
+			assert.False(t, ac.CanRead(user, web), strategy.String())
+		}
+
+		ac := permission.NewAccessControl(permission.WithStrategy(permission.AllowOverrides))
+		user, _, web, _ := setup(ac)
+		ac.Allow(user, web, permission.Read)
+		assert.True(t, ac.CanRead(user, web))
+		assert.False(t, ac.CanUpdate(user, web))
+	})
+
+	t.Run("More specific grant wins", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		user, _, web, comment := setup(ac)
+		ac.Allow(user, comment, permission.Read)
+
+		assert.True(t, ac.CanRead(user, comment))
+		assert.False(t, ac.CanRead(user, web))
+		assert.False(t, ac.CanUpdate(user, comment))
+	})
+}
@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenants(t *testing.T) {
+
+	t.Run("Registry per tenant", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		acmeAlice := ac.CreateEntityInTenant("acme", "alice")
+		globexAlice := ac.CreateEntityInTenant("globex", "alice")
+		acmeDocs := ac.CreateResourceInTenant("acme", "docs")
+		acmeReport := acmeDocs.CreateSub("report")
+		ac.CreateResourceInTenant("globex", "docs")
+		ac.CreateEntity("admin")
+
+		found, err := ac.GetEntityInTenant("acme", "alice")
+		assert.NoError(t, err)
+		assert.Same(t, acmeAlice, found)
+		found, err = ac.GetEntityInTenant("globex", "alice")
+		assert.NoError(t, err)
+		assert.Same(t, globexAlice, found)
+
+		_, err = ac.GetEntity("alice")
+		assert.ErrorIs(t, err, permission.ErrEntityNotFound)
+
+		report, err := ac.GetResourceInTenant("acme", "docs/report")
+		assert.NoError(t, err)
+		assert.Same(t, acmeReport, report)
+		assert.Equal(t, "acme", report.GetTenant())
+
+		_, err = ac.GetResource("docs")
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound)
+
+		assert.ErrorIs(t, ac.RegisterEntity(&permission.Entity{ID: "alice", Tenant: "acme"}), permission.ErrDuplicateEntity)
+		assert.Equal(t, []string{"acme", "globex"}, ac.Tenants())
+	})
+
+	t.Run("Hierarchies cannot cross tenants", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		editors := ac.CreateEntity("editors")
+		acmeAdmins := ac.CreateEntityInTenant("acme", "admins")
+		alice := ac.CreateEntityInTenant("acme", "alice")
+		bob := ac.CreateEntityInTenant("globex", "bob")
+
+		assert.NoError(t, alice.AddParents(editors), "global parent")
+		assert.NoError(t, alice.AddParents(acmeAdmins))
+		assert.ErrorIs(t, bob.AddParents(acmeAdmins), permission.ErrCrossTenant)
+		assert.ErrorIs(t, editors.AddParents(acmeAdmins), permission.ErrCrossTenant, "global entity under tenant entity")
+		assert.Equal(t, "acme", acmeAdmins.CreateChild("carol").Tenant)
+
+		acmeDocs := ac.CreateResourceInTenant("acme", "docs")
+		foreign := permission.NewResource("foreign")
+		foreign.Tenant = "globex"
+		assert.ErrorIs(t, acmeDocs.AttachSubs(foreign), permission.ErrCrossTenant)
+		assert.NoError(t, acmeDocs.AttachSubs(permission.NewResource("local")))
+	})
+
+	t.Run("Grants cannot cross tenants", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		editors := ac.CreateEntity("editors")
+		alice := ac.CreateEntityInTenant("acme", "alice")
+		bob := ac.CreateEntityInTenant("globex", "bob")
+		acmeDocs := ac.CreateResourceInTenant("acme", "docs")
+		shared := ac.CreateResource("shared")
+
+		assert.NoError(t, ac.ValidateGrant(alice, acmeDocs, permission.Read))
+		assert.NoError(t, ac.ValidateGrant(alice, shared, permission.Read))
+		assert.ErrorIs(t, ac.ValidateGrant(bob, acmeDocs, permission.Read), permission.ErrCrossTenant)
+		assert.ErrorIs(t, ac.ValidateGrant(editors, acmeDocs, permission.Read), permission.ErrCrossTenant)
+		assert.Panics(t, func() { ac.Allow(bob, acmeDocs, permission.Read) })
+		assert.Panics(t, func() { ac.DenyIf(editors, acmeDocs, permission.Read) })
+
+		bob.AddPerm(permission.Read, acmeDocs, true)
+		assert.False(t, ac.CanRead(bob, acmeDocs), "rules bypassing validation are ignored")
+		decision := ac.Explain(bob, acmeDocs, permission.Read)
+		assert.ErrorIs(t, decision.Err, permission.ErrCrossTenant)
+
+		bob.AllowPattern("docs/**", permission.Read)
+		assert.False(t, ac.CanRead(bob, acmeDocs), "tenant patterns do not match other tenants")
+	})
+
+	t.Run("Global roles apply within each tenant", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		editors := ac.CreateEntity("editors")
+		ac.AllowPattern(editors, "docs/**", permission.Update)
+		shared := ac.CreateResource("shared")
+		ac.Allow(editors, shared, permission.Read)
+
+		alice := ac.CreateEntityInTenant("acme", "alice")
+		alice.AddParents(editors)
+		bob := ac.CreateEntityInTenant("globex", "bob")
+		bob.AddParents(editors)
+		acmeReport := ac.CreateResourceInTenant("acme", "docs").CreateSub("report")
+		globexReport := ac.CreateResourceInTenant("globex", "docs").CreateSub("report")
+		ac.Deny(bob, globexReport, permission.Update)
+
+		assert.True(t, ac.CanInTenant("acme", alice, acmeReport, permission.Update))
+		assert.True(t, ac.CanInTenant("acme", alice, shared, permission.Read))
+		assert.False(t, ac.CanInTenant("globex", bob, globexReport, permission.Update))
+		assert.False(t, ac.CanInTenant("globex", alice, globexReport, permission.Update))
+		assert.False(t, ac.CanInTenant("globex", alice, acmeReport, permission.Update), "entity of another tenant")
+		assert.False(t, ac.CanUpdate(alice, globexReport))
+		assert.True(t, ac.CanInTenant("acme", editors, acmeReport, permission.Update))
+	})
+
+	t.Run("Serialization", func(t *testing.T) {
+		policy := `
+resources:
+  - id: docs
+    tenant: acme
+    owners: [alice]
+    resources:
+      - id: report
+  - id: docs
+    tenant: globex
+  - id: shared
+entities:
+  - id: editors
+    allow:
+      READ: [shared]
+  - id: alice
+    tenant: acme
+    parents: [editors]
+    allow:
+      UPDATE: [docs/report]
+  - id: alice
+    tenant: globex
+    allow:
+      READ: [docs, shared]
+`
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(policy))
+		if !assert.NoError(t, err) {
+			return
+		}
+		acmeAlice, _ := ac.GetEntityInTenant("acme", "alice")
+		globexAlice, _ := ac.GetEntityInTenant("globex", "alice")
+		acmeReport, _ := ac.GetResourceInTenant("acme", "docs/report")
+		globexDocs, _ := ac.GetResourceInTenant("globex", "docs")
+		assert.True(t, ac.CanInTenant("acme", acmeAlice, acmeReport, permission.Delete), "owner")
+		assert.True(t, ac.CanInTenant("acme", acmeAlice, ac.MustGetResource("shared"), permission.Read))
+		assert.True(t, ac.CanInTenant("globex", globexAlice, globexDocs, permission.Read))
+		assert.False(t, ac.CanInTenant("globex", globexAlice, globexDocs, permission.Update))
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		restored := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, restored))
+		again, err := json.Marshal(restored)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(data), string(again))
+
+		cross := "resources:\n  - id: docs\n    tenant: acme\nentities:\n  - id: bob\n    tenant: globex\n    allow:\n      READ: [docs]\n"
+		err = permission.ValidatePolicyYAML(strings.NewReader(cross))
+		assert.ErrorIs(t, err, permission.ErrResourceNotFound, "resources of other tenants are not visible")
+	})
+
+	t.Run("Role grants on tenant resources", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		acmeDocs := ac.CreateResourceInTenant("acme", "docs")
+		ac.CreateResourceInTenant("globex", "docs")
+		shared := ac.CreateResource("shared")
+		viewer := ac.CreateRole("viewer")
+		viewer.AllowOn(acmeDocs, permission.Read)
+		viewer.AllowOn(shared, permission.Read)
+		alice := ac.CreateEntityInTenant("acme", "alice")
+		ac.AssignRole(alice, viewer, nil)
+
+		data, err := json.Marshal(ac)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Contains(t, string(data), `"tenantGrants":{"acme":{"READ":["docs"]}}`)
+
+		restored := permission.NewAccessControl()
+		if !assert.NoError(t, json.Unmarshal(data, restored)) {
+			return
+		}
+		restoredAlice, _ := restored.GetEntityInTenant("acme", "alice")
+		restoredDocs, _ := restored.GetResourceInTenant("acme", "docs")
+		globexDocs, _ := restored.GetResourceInTenant("globex", "docs")
+		assert.True(t, restored.CanInTenant("acme", restoredAlice, restoredDocs, permission.Read))
+		assert.True(t, restored.CanRead(restoredAlice, restored.MustGetResource("shared")))
+		assert.False(t, restored.CanRead(restoredAlice, globexDocs))
+
+		again, err := json.Marshal(restored)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(data), string(again))
+	})
+
+	t.Run("Declared permissions with tenant grants", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DeclarePermission(permission.Read, "read documents", "documents")
+		acmeDocs := ac.CreateResourceInTenant("acme", "docs")
+		auditor := ac.CreateRole("auditor")
+		auditor.AllowOn(acmeDocs, "AUDIT")
+		alice := ac.CreateEntityInTenant("acme", "alice")
+		ac.AssignRole(alice, auditor, nil)
+
+		data, err := json.Marshal(ac)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		restored := permission.NewAccessControl()
+		if !assert.NoError(t, json.Unmarshal(data, restored), "permissions of tenant grants are declared") {
+			return
+		}
+		restoredAlice, _ := restored.GetEntityInTenant("acme", "alice")
+		restoredDocs, _ := restored.GetResourceInTenant("acme", "docs")
+		assert.True(t, restored.CanInTenant("acme", restoredAlice, restoredDocs, "AUDIT"))
+		assert.Equal(t, []permission.PermissionInfo{
+			{Permission: "AUDIT"},
+			{Permission: permission.Read, Description: "read documents", Category: "documents"},
+		}, restored.Permissions())
+	})
+}
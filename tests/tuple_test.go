@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func object(value string) permission.ObjectRef {
+	ref, err := permission.ParseObject(value)
+	if err != nil {
+		panic(err)
+	}
+	return ref
+}
+
+func subject(value string) permission.SubjectRef {
+	ref, err := permission.ParseSubject(value)
+	if err != nil {
+		panic(err)
+	}
+	return ref
+}
+
+func TestTuples(t *testing.T) {
+
+	t.Run("Parsing", func(t *testing.T) {
+		tuple, err := permission.ParseTuple("doc:readme#viewer@group:eng#member")
+		assert.NoError(t, err)
+		assert.Equal(t, permission.Tuple{
+			Object:   permission.ObjectRef{Type: "doc", ID: "readme"},
+			Relation: "viewer",
+			Subject:  permission.SubjectRef{Type: "group", ID: "eng", Relation: "member"},
+		}, tuple)
+		assert.Equal(t, "doc:readme#viewer@group:eng#member", tuple.String())
+
+		for _, value := range []string{"doc:readme#viewer", "doc:readme@user:alice", "doc#viewer@user:alice", "doc:readme#@user:alice", "doc:readme#viewer@user:alice#", ":readme#viewer@user:alice"} {
+			_, err := permission.ParseTuple(value)
+			assert.True(t, errors.Is(err, permission.ErrBadTuple), value)
+		}
+
+		rewrite, err := permission.ParseRewrite("parent->viewer")
+		assert.NoError(t, err)
+		assert.Equal(t, permission.TupleToUserset("parent", "viewer"), rewrite)
+		_, err = permission.ParseRewrite("->viewer")
+		assert.True(t, errors.Is(err, permission.ErrBadTuple))
+	})
+
+	t.Run("Direct tuples and usersets", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("doc:readme#viewer@user:alice")))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("doc:readme#viewer@group:eng#member")))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("group:eng#member@user:bob")))
+
+		assert.True(t, ac.Check(object("doc:readme"), "viewer", subject("user:alice")))
+		assert.True(t, ac.Check(object("doc:readme"), "viewer", subject("user:bob")))
+		assert.True(t, ac.Check(object("doc:readme"), "viewer", subject("group:eng#member")))
+		assert.False(t, ac.Check(object("doc:readme"), "viewer", subject("user:carol")))
+		assert.False(t, ac.Check(object("doc:readme"), "editor", subject("user:alice")))
+
+		ac.DeleteTuple(permission.MustParseTuple("group:eng#member@user:bob"))
+		assert.False(t, ac.Check(object("doc:readme"), "viewer", subject("user:bob")))
+		assert.Len(t, ac.Tuples(), 2)
+	})
+
+	t.Run("Deleting tuples", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		assert.NotPanics(t, func() {
+			ac.DeleteTuple(permission.MustParseTuple("doc:x#viewer@user:a"))
+		})
+		assert.Empty(t, ac.Tuples())
+
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("doc:x#viewer@user:a")))
+		ac.DeleteTuple(permission.MustParseTuple("doc:x#editor@user:a"))
+		ac.DeleteTuple(permission.MustParseTuple("doc:x#viewer@user:b"))
+		assert.Len(t, ac.Tuples(), 1)
+
+		ac.DeleteTuple(permission.MustParseTuple("doc:x#viewer@user:a"))
+		assert.Empty(t, ac.Tuples())
+		assert.False(t, ac.Check(object("doc:x"), "viewer", subject("user:a")))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("doc:x#viewer@user:a")))
+		assert.True(t, ac.Check(object("doc:x"), "viewer", subject("user:a")))
+	})
+
+	t.Run("Rewrites", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineRelation("doc", "editor")
+		ac.DefineRelation("doc", "viewer", permission.ComputedUserset("editor"), permission.TupleToUserset("parent", "viewer"))
+		ac.DefineRelation("folder", "viewer", permission.TupleToUserset("parent", "viewer"))
+
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("doc:readme#editor@user:alice")))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("doc:readme#parent@folder:handbook")))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("folder:handbook#parent@folder:root")))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("folder:root#viewer@user:bob")))
+
+		assert.True(t, ac.Check(object("doc:readme"), "viewer", subject("user:alice")), "viewer includes editor")
+		assert.True(t, ac.Check(object("doc:readme"), "viewer", subject("user:bob")), "viewer of the parent folder")
+		assert.False(t, ac.Check(object("doc:readme"), "editor", subject("user:bob")))
+
+		err := ac.WriteTuple(permission.MustParseTuple("doc:readme#commenter@user:alice"))
+		assert.True(t, errors.Is(err, permission.ErrUnknownRelation))
+		assert.Equal(t, []permission.Rewrite{permission.ComputedUserset("editor"), permission.TupleToUserset("parent", "viewer")}, ac.Relations("doc")["viewer"])
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineRelation("folder", "viewer", permission.TupleToUserset("parent", "viewer"))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("folder:a#parent@folder:b")))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("folder:b#parent@folder:a")))
+
+		assert.False(t, ac.Check(object("folder:a"), "viewer", subject("user:alice")))
+	})
+
+	t.Run("Bridge to the graph", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		editors := ac.CreateEntity("editors")
+		alice := editors.CreateChild("alice")
+		ac.AddEntity(alice)
+		docs := ac.CreateResource("docs")
+		readme := docs.CreateSub("readme")
+		readme.Type = "document"
+		docs.AddOwners(editors)
+
+		ac.DefineResourceType("document", []permission.Permission{permission.Read}, []string{"viewer"})
+		ac.DefineRelation("document", "viewer", permission.TupleToUserset(permission.ParentRelation, permission.OwnerRelation))
+
+		assert.Equal(t, "document:docs/readme", permission.ResourceObject(readme).String())
+		assert.Equal(t, "resource:docs", permission.ResourceObject(docs).String())
+		assert.True(t, ac.Check(permission.ResourceObject(docs), permission.OwnerRelation, permission.EntitySubject(alice)))
+		assert.True(t, ac.Check(object("entity:editors"), permission.MemberRelation, permission.EntitySubject(alice)))
+		assert.True(t, ac.Check(permission.ResourceObject(readme), "viewer", permission.EntitySubject(alice)), "owners of the parent")
+		assert.False(t, ac.Check(permission.ResourceObject(readme), permission.OwnerRelation, permission.EntitySubject(alice)))
+
+		assert.NoError(t, ac.WriteTuple(permission.Tuple{Object: permission.ResourceObject(readme), Relation: permission.OwnerRelation, Subject: subject("user:bob")}))
+		assert.True(t, ac.Check(permission.ResourceObject(readme), permission.OwnerRelation, subject("user:bob")))
+		err := ac.WriteTuple(permission.Tuple{Object: permission.ResourceObject(readme), Relation: "editor", Subject: subject("user:bob")})
+		assert.True(t, errors.Is(err, permission.ErrUnknownRelation))
+	})
+
+	t.Run("Serialization", func(t *testing.T) {
+		ac := permission.NewAccessControl()
+		ac.DefineRelation("doc", "editor")
+		ac.DefineRelation("doc", "viewer", permission.ComputedUserset("editor"))
+		assert.NoError(t, ac.WriteTuple(permission.MustParseTuple("doc:readme#editor@user:alice")))
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"relations":{"doc":{"editor":[],"viewer":["editor"]}},"tuples":["doc:readme#editor@user:alice"]`)
+
+		loaded := permission.NewAccessControl()
+		assert.NoError(t, json.Unmarshal(data, loaded))
+		assert.True(t, loaded.Check(object("doc:readme"), "viewer", subject("user:alice")))
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(`
+relations:
+  doc:
+    viewer: [parent->viewer]
+tuples:
+  - doc:readme#parent@folder:handbook
+  - folder:handbook#viewer@user:alice
+`))
+		assert.NoError(t, err)
+		assert.True(t, ac.Check(object("doc:readme"), "viewer", subject("user:alice")))
+
+		_, err = permission.LoadPolicyYAML(strings.NewReader(`
+tuples:
+  - doc:readme#viewer
+`))
+		assert.True(t, errors.Is(err, permission.ErrBadTuple))
+	})
+}
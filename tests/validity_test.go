@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gouef/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeBoundGrants(t *testing.T) {
+	start := time.Date(2026, time.March, 2, 8, 0, 0, 0, time.UTC) // Monday
+	now := start
+	clock := func() time.Time { return now }
+
+	t.Run("Validity", func(t *testing.T) {
+		now = start
+		ac := permission.NewAccessControl(permission.WithClock(clock))
+		repository := ac.CreateResource("repository")
+		contractor := ac.CreateEntity("contractor")
+		ac.AllowIf(contractor, repository, permission.Read, permission.Validity{
+			NotBefore: start.Add(24 * time.Hour),
+			NotAfter:  start.Add(72 * time.Hour),
+		})
+
+		assert.False(t, ac.CanRead(contractor, repository), "not active yet")
+		now = start.Add(24 * time.Hour)
+		assert.True(t, ac.CanRead(contractor, repository), "bounds are inclusive")
+		now = start.Add(72 * time.Hour)
+		assert.True(t, ac.CanRead(contractor, repository))
+		now = start.Add(72*time.Hour + time.Second)
+		assert.False(t, ac.CanRead(contractor, repository), "expired")
+	})
+
+	t.Run("Expired deny no longer applies", func(t *testing.T) {
+		now = start
+		ac := permission.NewAccessControl(permission.WithClock(clock))
+		web := ac.CreateResource("web")
+		users := ac.CreateEntity("users")
+		user := users.CreateChild("user")
+		ac.Allow(users, web, permission.Update)
+		ac.DenyIf(user, web, permission.Update, permission.Validity{NotAfter: start.Add(time.Hour)})
+
+		assert.False(t, ac.CanUpdate(user, web))
+		ac.SetClock(func() time.Time { return start.Add(2 * time.Hour) })
+		assert.True(t, ac.CanUpdate(user, web))
+	})
+
+	t.Run("Schedule", func(t *testing.T) {
+		prague, err := time.LoadLocation("Europe/Prague")
+		if !assert.NoError(t, err) {
+			return
+		}
+		officeHours := permission.Schedule{Days: permission.Weekdays(), Start: 9 * time.Hour, End: 17 * time.Hour, Location: prague}
+
+		ac := permission.NewAccessControl(permission.WithClock(clock))
+		intranet := ac.CreateResource("intranet")
+		staff := ac.CreateEntity("staff")
+		ac.AllowIf(staff, intranet, permission.Read, officeHours)
+
+		cases := map[time.Time]bool{
+			time.Date(2026, time.March, 2, 9, 0, 0, 0, prague):    true,
+			time.Date(2026, time.March, 2, 16, 59, 0, 0, prague):  true,
+			time.Date(2026, time.March, 2, 17, 0, 0, 0, prague):   false,
+			time.Date(2026, time.March, 2, 8, 30, 0, 0, time.UTC): true, // 9:30 in Prague
+			time.Date(2026, time.March, 2, 8, 59, 0, 0, prague):   false,
+			time.Date(2026, time.March, 7, 10, 0, 0, 0, prague):   false, // Saturday
+		}
+		for at, expected := range cases {
+			now = at
+			assert.Equal(t, expected, ac.CanRead(staff, intranet), at.String())
+		}
+
+		night := permission.Schedule{Start: 22 * time.Hour, End: 6 * time.Hour}
+		ac.AllowIf(staff, intranet, permission.Update, night)
+		now = time.Date(2026, time.March, 7, 23, 0, 0, 0, time.UTC)
+		assert.True(t, ac.CanUpdate(staff, intranet))
+		now = time.Date(2026, time.March, 8, 5, 59, 0, 0, time.UTC)
+		assert.True(t, ac.CanUpdate(staff, intranet))
+		now = time.Date(2026, time.March, 8, 12, 0, 0, 0, time.UTC)
+		assert.False(t, ac.CanUpdate(staff, intranet))
+
+		fridayNight := permission.Schedule{Days: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 6 * time.Hour}
+		ac.AllowIf(staff, intranet, permission.Delete, fridayNight)
+		nights := map[time.Time]bool{
+			time.Date(2026, time.March, 6, 2, 0, 0, 0, time.UTC):  false, // Friday, window of Thursday
+			time.Date(2026, time.March, 6, 23, 0, 0, 0, time.UTC): true,
+			time.Date(2026, time.March, 7, 2, 0, 0, 0, time.UTC):  true, // Saturday, window of Friday
+			time.Date(2026, time.March, 7, 6, 0, 0, 0, time.UTC):  false,
+			time.Date(2026, time.March, 7, 23, 0, 0, 0, time.UTC): false,
+		}
+		for at, expected := range nights {
+			now = at
+			assert.Equal(t, expected, ac.CanDelete(staff, intranet), at.String())
+		}
+	})
+
+	t.Run("Schedule across daylight saving changes", func(t *testing.T) {
+		prague, err := time.LoadLocation("Europe/Prague")
+		if !assert.NoError(t, err) {
+			return
+		}
+		ac := permission.NewAccessControl(permission.WithClock(clock))
+		intranet := ac.CreateResource("intranet")
+		staff := ac.CreateEntity("staff")
+		ac.AllowIf(staff, intranet, permission.Read, permission.Schedule{Start: 9 * time.Hour, End: 17 * time.Hour, Location: prague})
+
+		cases := map[time.Time]bool{
+			time.Date(2026, time.March, 29, 8, 30, 0, 0, prague):   false,
+			time.Date(2026, time.March, 29, 9, 30, 0, 0, prague):   true,
+			time.Date(2026, time.March, 29, 16, 59, 0, 0, prague):  true,
+			time.Date(2026, time.March, 29, 17, 30, 0, 0, prague):  false,
+			time.Date(2026, time.October, 25, 8, 30, 0, 0, prague): false,
+			time.Date(2026, time.October, 25, 9, 0, 0, 0, prague):  true,
+			time.Date(2026, time.October, 25, 17, 0, 0, 0, prague): false,
+		}
+		for at, expected := range cases {
+			now = at
+			assert.Equal(t, expected, ac.CanRead(staff, intranet), at.String())
+		}
+	})
+
+	t.Run("Expressions use the clock", func(t *testing.T) {
+		now = time.Date(2026, time.March, 2, 20, 0, 0, 0, time.UTC)
+		ac := permission.NewAccessControl(permission.WithClock(clock))
+		web := ac.CreateResource("web")
+		user := ac.CreateEntity("user")
+		ac.AllowIf(user, web, permission.Read, permission.MustCompile(`time.hour < 18`))
+
+		assert.False(t, ac.CanRead(user, web))
+		now = time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC)
+		assert.True(t, ac.CanRead(user, web))
+	})
+
+	t.Run("Purge expired", func(t *testing.T) {
+		now = start
+		ac := permission.NewAccessControl(permission.WithClock(clock))
+		web := ac.CreateResource("web")
+		group := ac.CreateEntity("group")
+		user := group.CreateChild("user")
+		ac.AllowIf(user, web, permission.Read, permission.Validity{NotAfter: start.Add(time.Hour)})
+		ac.AllowIf(group, web, permission.Update, permission.Validity{NotAfter: start.Add(48 * time.Hour)})
+		user.AddPermPatternIf(permission.Delete, "web/*", true, permission.Validity{NotAfter: start.Add(time.Minute)})
+		ac.Allow(user, web, permission.Create)
+
+		assert.Equal(t, 0, ac.PurgeExpired())
+
+		now = start.Add(2 * time.Hour)
+		assert.Equal(t, 2, ac.PurgeExpired())
+		_, ok := user.Permission[permission.Read][web]
+		assert.False(t, ok)
+		_, ok = user.Patterns[permission.Delete]["web/*"]
+		assert.False(t, ok)
+		assert.True(t, ac.CanCreate(user, web))
+		assert.True(t, ac.CanUpdate(user, web))
+
+		now = start.Add(49 * time.Hour)
+		assert.Equal(t, 1, ac.PurgeExpired())
+		assert.Equal(t, 0, ac.PurgeExpired())
+	})
+
+	t.Run("Policy documents", func(t *testing.T) {
+		policy := `
+resources:
+  - id: repository
+entities:
+  - id: contractor
+    rules:
+      - allow: READ
+        resource: repository
+        notBefore: 2026-03-01T00:00:00Z
+        notAfter: 2026-03-31T23:59:59Z
+        schedule:
+          days: [Mon, Tue, Wed, Thu, Fri]
+          start: "09:00"
+          end: "17:00"
+          timezone: Europe/Prague
+`
+		ac, err := permission.LoadPolicyYAML(strings.NewReader(policy))
+		if !assert.NoError(t, err) {
+			return
+		}
+		contractor := ac.MustGetEntity("contractor")
+		repository := ac.MustGetResource("repository")
+
+		ac.SetClock(func() time.Time { return time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC) })
+		assert.True(t, ac.CanRead(contractor, repository))
+		ac.SetClock(func() time.Time { return time.Date(2026, time.April, 1, 10, 0, 0, 0, time.UTC) })
+		assert.False(t, ac.CanRead(contractor, repository))
+
+		data, err := json.Marshal(ac)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"resources": [{"id": "repository"}],
+			"entities": [{"id": "contractor", "rules": [{
+				"allow": "READ",
+				"resource": "repository",
+				"notBefore": "2026-03-01T00:00:00Z",
+				"notAfter": "2026-03-31T23:59:59Z",
+				"schedule": {"days": ["Monday", "Tuesday", "Wednesday", "Thursday", "Friday"], "start": "09:00", "end": "17:00", "timezone": "Europe/Prague"}
+			}]}]
+		}`, string(data))
+
+		restored := permission.NewAccessControl(permission.WithClock(func() time.Time { return time.Date(2026, time.March, 7, 10, 0, 0, 0, time.UTC) }))
+		assert.NoError(t, json.Unmarshal(data, restored))
+		assert.False(t, restored.CanRead(restored.MustGetEntity("contractor"), restored.MustGetResource("repository")), "Saturday")
+
+		for _, schedule := range []string{"start: '9'", "start: '25:00'", "days: [Someday]", "start: '09:00'\n          end: '17:00'\n          timezone: Mars/Olympus"} {
+			invalid := "resources:\n  - id: web\nentities:\n  - id: alice\n    rules:\n      - allow: READ\n        resource: web\n        schedule:\n          " + schedule + "\n"
+			var policyErr *permission.PolicyError
+			err := permission.ValidatePolicyYAML(strings.NewReader(invalid))
+			if assert.ErrorAs(t, err, &policyErr, schedule) {
+				assert.Equal(t, 6, policyErr.Line)
+			}
+		}
+	})
+}
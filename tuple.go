@@ -0,0 +1,448 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// EntityObjectType is the object type of registered entities, e.g. entity:alice.
+	EntityObjectType = "entity"
+	// ResourceObjectType is the object type of registered resources without a
+	// Type, identified by their path, e.g. resource:web/comments.
+	ResourceObjectType = "resource"
+
+	// OwnerRelation relates a resource object to the members of its Owners.
+	OwnerRelation = "owner"
+	// ParentRelation relates a resource object to its parent resource.
+	ParentRelation = "parent"
+	// MemberRelation relates an entity object to itself and its descendants.
+	MemberRelation = "member"
+)
+
+// ObjectRef identifies an object of a relation tuple, like doc:readme.
+type ObjectRef struct {
+	Type string
+	ID   string
+}
+
+func (o ObjectRef) String() string {
+	return o.Type + ":" + o.ID
+}
+
+// SubjectRef is the subject of a relation tuple, an object like user:alice or
+// a userset like group:eng#member, meaning every subject with the relation to
+// the object.
+type SubjectRef struct {
+	Type     string
+	ID       string
+	Relation string
+}
+
+func (s SubjectRef) String() string {
+	if s.Relation == "" {
+		return s.Object().String()
+	}
+	return s.Object().String() + "#" + s.Relation
+}
+
+// Object returns the object of the subject, without the userset relation.
+func (s SubjectRef) Object() ObjectRef {
+	return ObjectRef{Type: s.Type, ID: s.ID}
+}
+
+// Tuple states that the subject has the relation to the object, written as
+// object#relation@subject, e.g. doc:readme#viewer@user:alice.
+type Tuple struct {
+	Object   ObjectRef
+	Relation string
+	Subject  SubjectRef
+}
+
+func (t Tuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+// ParseObject parses an object reference of the form type:id.
+//
+// Example:
+//
+//	readme, err := permission.ParseObject("doc:readme")
+func ParseObject(value string) (ObjectRef, error) {
+	objectType, id, ok := strings.Cut(value, ":")
+	if !ok || objectType == "" || id == "" || strings.ContainsAny(value, "#@") {
+		return ObjectRef{}, fmt.Errorf("%w: object %q", ErrBadTuple, value)
+	}
+	return ObjectRef{Type: objectType, ID: id}, nil
+}
+
+// ParseSubject parses a subject of the form type:id or type:id#relation.
+//
+// Example:
+//
+//	members, err := permission.ParseSubject("group:eng#member")
+func ParseSubject(value string) (SubjectRef, error) {
+	object, relation, hasRelation := strings.Cut(value, "#")
+	ref, err := ParseObject(object)
+	if err != nil || (hasRelation && relation == "") {
+		return SubjectRef{}, fmt.Errorf("%w: subject %q", ErrBadTuple, value)
+	}
+	return SubjectRef{Type: ref.Type, ID: ref.ID, Relation: relation}, nil
+}
+
+// ParseTuple parses a tuple of the form object#relation@subject.
+//
+// Example:
+//
+//	tuple, err := permission.ParseTuple("doc:readme#viewer@user:alice")
+func ParseTuple(value string) (Tuple, error) {
+	left, right, ok := strings.Cut(value, "@")
+	object, relation, hasRelation := strings.Cut(left, "#")
+	if !ok || !hasRelation || relation == "" {
+		return Tuple{}, fmt.Errorf("%w: %q", ErrBadTuple, value)
+	}
+
+	objectRef, err := ParseObject(object)
+	if err != nil {
+		return Tuple{}, fmt.Errorf("%w: %q", ErrBadTuple, value)
+	}
+	subject, err := ParseSubject(right)
+	if err != nil {
+		return Tuple{}, fmt.Errorf("%w: %q", ErrBadTuple, value)
+	}
+	return Tuple{Object: objectRef, Relation: relation, Subject: subject}, nil
+}
+
+// MustParseTuple is like ParseTuple but panics when the tuple is malformed.
+//
+// Example:
+//
+//	ac.WriteTuple(permission.MustParseTuple("doc:readme#viewer@user:alice"))
+func MustParseTuple(value string) Tuple {
+	tuple, err := ParseTuple(value)
+	if err != nil {
+		panic(err)
+	}
+	return tuple
+}
+
+// ResourceObject returns the object of a registered resource: its Type (or
+// ResourceObjectType when it has none) and its path.
+//
+// Example:
+//
+//	permission.ResourceObject(readme) // resource:docs/readme
+func ResourceObject(resource *Resource) ObjectRef {
+	objectType := resource.Type
+	if objectType == "" {
+		objectType = ResourceObjectType
+	}
+	return ObjectRef{Type: objectType, ID: resource.Path()}
+}
+
+// EntitySubject returns the subject of a registered entity.
+//
+// Example:
+//
+//	permission.EntitySubject(alice) // entity:alice
+func EntitySubject(entity *Entity) SubjectRef {
+	return SubjectRef{Type: EntityObjectType, ID: entity.ID}
+}
+
+// Rewrite adds subjects to a relation: a computed userset adds the subjects
+// of another relation of the same object, a tuple-to-userset adds the
+// subjects of Relation of every object related by Tupleset.
+type Rewrite struct {
+	// Tupleset is empty for a computed userset.
+	Tupleset string
+	Relation string
+}
+
+// ComputedUserset makes a relation include another relation of the object,
+// e.g. every editor is a viewer.
+//
+// Example:
+//
+//	ac.DefineRelation("doc", "viewer", permission.ComputedUserset("editor"))
+func ComputedUserset(relation string) Rewrite {
+	return Rewrite{Relation: relation}
+}
+
+// TupleToUserset makes a relation include the relation of objects related by
+// the tupleset, e.g. viewers of the parent folder are viewers of a document.
+//
+// Example:
+//
+//	ac.DefineRelation("doc", "viewer", permission.TupleToUserset("parent", "viewer"))
+func TupleToUserset(tupleset, relation string) Rewrite {
+	return Rewrite{Tupleset: tupleset, Relation: relation}
+}
+
+// String returns "relation" for a computed userset and "tupleset->relation"
+// for a tuple-to-userset.
+func (r Rewrite) String() string {
+	if r.Tupleset == "" {
+		return r.Relation
+	}
+	return r.Tupleset + "->" + r.Relation
+}
+
+// ParseRewrite parses a rewrite in the form returned by Rewrite.String.
+//
+// Example:
+//
+//	rewrite, err := permission.ParseRewrite("parent->viewer")
+func ParseRewrite(value string) (Rewrite, error) {
+	tupleset, relation, ok := strings.Cut(value, "->")
+	if !ok {
+		tupleset, relation = "", value
+	}
+	if relation == "" || (ok && tupleset == "") || strings.ContainsAny(value, ":#@") {
+		return Rewrite{}, fmt.Errorf("%w: rewrite %q", ErrBadTuple, value)
+	}
+	return Rewrite{Tupleset: tupleset, Relation: relation}, nil
+}
+
+// DefineRelation sets the rewrites of a relation of objects of the type,
+// defining it again replaces them. Direct tuples of the relation always count.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.DefineRelation("doc", "editor")
+//	ac.DefineRelation("doc", "viewer", permission.ComputedUserset("editor"), permission.TupleToUserset("parent", "viewer"))
+//	ac.DefineRelation("folder", "viewer")
+func (ac *AccessControl) DefineRelation(objectType, relation string, rewrites ...Rewrite) *AccessControl {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.relations == nil {
+		ac.relations = make(map[string]map[string][]Rewrite)
+	}
+	if ac.relations[objectType] == nil {
+		ac.relations[objectType] = make(map[string][]Rewrite)
+	}
+	ac.relations[objectType][relation] = slices.Clone(rewrites)
+	return ac
+}
+
+// Relations returns the defined relations of the object type with their rewrites.
+//
+// Example:
+//
+//	ac.Relations("doc") // map[editor:[] viewer:[editor parent->viewer]]
+func (ac *AccessControl) Relations(objectType string) map[string][]Rewrite {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	relations := make(map[string][]Rewrite, len(ac.relations[objectType]))
+	for relation, rewrites := range ac.relations[objectType] {
+		relations[relation] = slices.Clone(rewrites)
+	}
+	return relations
+}
+
+// WriteTuple stores a relation tuple, writing it again is a no-op. When the
+// object type has defined relations or is a resource type with relations,
+// the relation must be one of them, owner or parent, otherwise it returns
+// ErrUnknownRelation.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	err := ac.WriteTuple(permission.MustParseTuple("doc:readme#parent@folder:handbook"))
+func (ac *AccessControl) WriteTuple(tuple Tuple) error {
+	if tuple.Object.Type == "" || tuple.Object.ID == "" || tuple.Relation == "" || tuple.Subject.Type == "" || tuple.Subject.ID == "" {
+		return fmt.Errorf("%w: %q", ErrBadTuple, tuple)
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if !ac.knownRelation(tuple.Object.Type, tuple.Relation) {
+		return fmt.Errorf("%w: %s of %s", ErrUnknownRelation, tuple.Relation, tuple.Object.Type)
+	}
+	if ac.tuples == nil {
+		ac.tuples = make(map[ObjectRef]map[string][]SubjectRef)
+	}
+	if ac.tuples[tuple.Object] == nil {
+		ac.tuples[tuple.Object] = make(map[string][]SubjectRef)
+	}
+	if !slices.Contains(ac.tuples[tuple.Object][tuple.Relation], tuple.Subject) {
+		ac.tuples[tuple.Object][tuple.Relation] = append(ac.tuples[tuple.Object][tuple.Relation], tuple.Subject)
+	}
+	return nil
+}
+
+// DeleteTuple removes a stored relation tuple.
+//
+// Example:
+//
+//	ac.DeleteTuple(permission.MustParseTuple("doc:readme#viewer@user:alice"))
+func (ac *AccessControl) DeleteTuple(tuple Tuple) *AccessControl {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	relations := ac.tuples[tuple.Object]
+	if relations == nil {
+		return ac
+	}
+	relations[tuple.Relation] = slices.DeleteFunc(relations[tuple.Relation], func(s SubjectRef) bool { return s == tuple.Subject })
+	if len(relations[tuple.Relation]) == 0 {
+		delete(relations, tuple.Relation)
+	}
+	if len(relations) == 0 {
+		delete(ac.tuples, tuple.Object)
+	}
+	return ac
+}
+
+// Tuples returns the stored relation tuples sorted by their string form.
+// Relations derived from owners, resource parents and entity members are not
+// included.
+//
+// Example:
+//
+//	for _, tuple := range ac.Tuples() {
+//		fmt.Println(tuple) // doc:readme#viewer@user:alice
+//	}
+func (ac *AccessControl) Tuples() []Tuple {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	var tuples []Tuple
+	for object, relations := range ac.tuples {
+		for relation, subjects := range relations {
+			for _, subject := range subjects {
+				tuples = append(tuples, Tuple{Object: object, Relation: relation, Subject: subject})
+			}
+		}
+	}
+	sort.Slice(tuples, func(i, j int) bool { return tuples[i].String() < tuples[j].String() })
+	return tuples
+}
+
+// Check reports whether the subject has the relation to the object, through
+// stored tuples, usersets and rewrites of the relation. The existing graph is
+// bridged into tuples: a resource object relates to its Owners (and their
+// members) by owner and to its parent resource by parent, an entity object
+// relates to itself and its descendants by member.
+//
+// Example:
+//
+//	ac := permission.NewAccessControl()
+//	ac.DefineRelation("doc", "viewer", permission.ComputedUserset("editor"), permission.TupleToUserset("parent", "viewer"))
+//	ac.WriteTuple(permission.MustParseTuple("doc:readme#parent@folder:handbook"))
+//	ac.WriteTuple(permission.MustParseTuple("folder:handbook#viewer@user:alice"))
+//	readme, _ := permission.ParseObject("doc:readme")
+//	alice, _ := permission.ParseSubject("user:alice")
+//	ac.Check(readme, "viewer", alice) // true
+func (ac *AccessControl) Check(object ObjectRef, relation string, subject SubjectRef) bool {
+	return ac.check(object, relation, subject, make(map[relationKey]bool))
+}
+
+// relationKey is a relation of an object visited by Check.
+type relationKey struct {
+	object   ObjectRef
+	relation string
+}
+
+func (ac *AccessControl) check(object ObjectRef, relation string, subject SubjectRef, visited map[relationKey]bool) bool {
+	key := relationKey{object: object, relation: relation}
+	if visited[key] {
+		return false
+	}
+	visited[key] = true
+
+	if subject.Relation == relation && subject.Object() == object {
+		return true
+	}
+	for _, s := range ac.relatedSubjects(object, relation) {
+		if s == subject {
+			return true
+		}
+		if s.Relation != "" && ac.check(s.Object(), s.Relation, subject, visited) {
+			return true
+		}
+	}
+
+	ac.mu.RLock()
+	rewrites := ac.relations[object.Type][relation]
+	ac.mu.RUnlock()
+	for _, rewrite := range rewrites {
+		if rewrite.Tupleset == "" {
+			if ac.check(object, rewrite.Relation, subject, visited) {
+				return true
+			}
+			continue
+		}
+		for _, s := range ac.relatedSubjects(object, rewrite.Tupleset) {
+			if ac.check(s.Object(), rewrite.Relation, subject, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relatedSubjects returns the subjects of stored tuples of the relation
+// together with subjects derived from the graph.
+func (ac *AccessControl) relatedSubjects(object ObjectRef, relation string) []SubjectRef {
+	ac.mu.RLock()
+	subjects := slices.Clone(ac.tuples[object][relation])
+	ac.mu.RUnlock()
+
+	if object.Type == EntityObjectType && relation == MemberRelation {
+		if entity, err := ac.GetEntity(object.ID); err == nil {
+			subjects = append(subjects, EntitySubject(entity))
+			for _, child := range entity.GetChildren() {
+				subjects = append(subjects, SubjectRef{Type: EntityObjectType, ID: child.ID, Relation: MemberRelation})
+			}
+		}
+		return subjects
+	}
+
+	resource := ac.objectResource(object)
+	if resource == nil {
+		return subjects
+	}
+	switch relation {
+	case OwnerRelation:
+		for _, owner := range resource.GetOwners() {
+			subjects = append(subjects, SubjectRef{Type: EntityObjectType, ID: owner.ID, Relation: MemberRelation})
+		}
+	case ParentRelation:
+		if parent := resource.GetParent(); parent != nil {
+			parentObject := ResourceObject(parent)
+			subjects = append(subjects, SubjectRef{Type: parentObject.Type, ID: parentObject.ID})
+		}
+	}
+	return subjects
+}
+
+// objectResource finds the registered resource of an object, nil when there
+// is none.
+func (ac *AccessControl) objectResource(object ObjectRef) *Resource {
+	resource, err := ac.GetResource(object.ID)
+	if err != nil || ResourceObject(resource).Type != object.Type {
+		return nil
+	}
+	return resource
+}
+
+// knownRelation reports whether tuples of the relation may be written for
+// objects of the type. The caller must hold ac.mu.
+func (ac *AccessControl) knownRelation(objectType, relation string) bool {
+	resourceType, typed := ac.types[objectType]
+	defined, hasDefinitions := ac.relations[objectType]
+	if (!typed || len(resourceType.Relations) == 0) && !hasDefinitions {
+		return true
+	}
+	if relation == OwnerRelation || relation == ParentRelation {
+		return true
+	}
+	_, ok := defined[relation]
+	return ok || (typed && slices.Contains(resourceType.Relations, relation))
+}
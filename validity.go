@@ -0,0 +1,265 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Validity is a condition met between NotBefore and NotAfter, both inclusive.
+// A zero bound is open.
+//
+// Example:
+//
+//	contract := permission.Validity{NotAfter: time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)}
+//	ac.AllowIf(contractor, repository, permission.Read, contract)
+type Validity struct {
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// Evaluate implements Condition with the time of the request.
+func (v Validity) Evaluate(ctx context.Context, request *Request) (bool, error) {
+	now := requestTime(request)
+	if !v.NotBefore.IsZero() && now.Before(v.NotBefore) {
+		return false, nil
+	}
+	return !v.Expired(now), nil
+}
+
+// Expired reports whether the validity ended before now.
+//
+// Example:
+//
+//	permission.Validity{NotAfter: yesterday}.Expired(time.Now()) // true
+func (v Validity) Expired(now time.Time) bool {
+	return !v.NotAfter.IsZero() && now.After(v.NotAfter)
+}
+
+// Schedule is a condition met on recurring time windows, from Start to End
+// (wall clock times of day as durations, like 9 * time.Hour) on the given days
+// in Location. Empty Days means every day, a nil Location is UTC. A window with
+// End before Start spans midnight, like 22:00 to 06:00, and belongs to the day
+// it starts on: on Fridays it ends at 06:00 on Saturday.
+//
+// Example:
+//
+//	prague, _ := time.LoadLocation("Europe/Prague")
+//	officeHours := permission.Schedule{
+//		Days:     permission.Weekdays(),
+//		Start:    9 * time.Hour,
+//		End:      17 * time.Hour,
+//		Location: prague,
+//	}
+//	ac.AllowIf(staff, intranet, permission.Read, officeHours)
+type Schedule struct {
+	Days     []time.Weekday
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// Weekdays returns Monday to Friday.
+func Weekdays() []time.Weekday {
+	return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+}
+
+// Evaluate implements Condition with the time of the request.
+func (s Schedule) Evaluate(ctx context.Context, request *Request) (bool, error) {
+	location := s.Location
+	if location == nil {
+		location = time.UTC
+	}
+	now := requestTime(request).In(location)
+
+	clock := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute +
+		time.Duration(now.Second())*time.Second + time.Duration(now.Nanosecond())
+	day := now.Weekday()
+	if s.End < s.Start && clock < s.End {
+		// The window started the day before.
+		day = (day + 6) % 7
+	}
+	if len(s.Days) > 0 && !slices.Contains(s.Days, day) {
+		return false, nil
+	}
+
+	if s.End < s.Start {
+		return clock >= s.Start || clock < s.End, nil
+	}
+	return clock >= s.Start && clock < s.End, nil
+}
+
+func requestTime(request *Request) time.Time {
+	if request.Time.IsZero() {
+		return time.Now()
+	}
+	return request.Time
+}
+
+// WithClock sets the function returning the time of permission checks,
+// time.Now by default.
+//
+// Example:
+//
+//	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+//	ac := permission.NewAccessControl(permission.WithClock(func() time.Time { return now }))
+func WithClock(clock func() time.Time) Option {
+	return func(ac *AccessControl) {
+		ac.clock = clock
+	}
+}
+
+// SetClock changes the function returning the time of permission checks, nil
+// restores time.Now.
+//
+// Example:
+//
+//	ac.SetClock(func() time.Time { return time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC) })
+func (ac *AccessControl) SetClock(clock func() time.Time) *AccessControl {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.clock = clock
+	return ac
+}
+
+// now returns the current time of the clock.
+func (ac *AccessControl) now() time.Time {
+	ac.mu.RLock()
+	clock := ac.clock
+	ac.mu.RUnlock()
+
+	if clock == nil {
+		return time.Now()
+	}
+	return clock()
+}
+
+// PurgeExpired removes rules with an expired Validity from all entities
+// reachable from the registered ones and returns the number of removed rules.
+//
+// Example:
+//
+//	removed := ac.PurgeExpired()
+func (ac *AccessControl) PurgeExpired() int {
+	ac.mu.RLock()
+	entities := collectEntities(ac.Entities)
+	ac.mu.RUnlock()
+
+	now := ac.now()
+	removed := 0
+	for _, entity := range entities {
+		removed += entity.PurgeExpired(now)
+	}
+	return removed
+}
+
+// PurgeExpired removes rules with a Validity expired at now and returns the
+// number of removed rules.
+//
+// Example:
+//
+//	contractor.PurgeExpired(time.Now())
+func (e *Entity) PurgeExpired(now time.Time) int {
+	removed := e.purgeExpired(now)
+	if removed > 0 {
+		e.touch()
+	}
+	return removed
+}
+
+// purgeExpired removes the expired rules under the lock of the entity.
+func (e *Entity) purgeExpired(now time.Time) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	removed := 0
+	for key, conditions := range e.conditions {
+		expired := slices.ContainsFunc(conditions, func(condition Condition) bool {
+			validity, ok := condition.(Validity)
+			return ok && validity.Expired(now)
+		})
+		if !expired {
+			continue
+		}
+
+		if key.resource != nil {
+			delete(e.Permission[key.permission], key.resource)
+		} else {
+			delete(e.Patterns[key.permission], key.pattern)
+		}
+		delete(e.conditions, key)
+		removed++
+	}
+	return removed
+}
+
+// scheduleDocument is the serialized form of a Schedule, with day names,
+// "15:04" times and a time zone name.
+type scheduleDocument struct {
+	Days     []string `json:"days,omitempty" yaml:"days"`
+	Start    string   `json:"start" yaml:"start"`
+	End      string   `json:"end" yaml:"end"`
+	Timezone string   `json:"timezone,omitempty" yaml:"timezone"`
+}
+
+func scheduleToDocument(s Schedule) *scheduleDocument {
+	doc := &scheduleDocument{Start: formatClock(s.Start), End: formatClock(s.End)}
+	for _, day := range s.Days {
+		doc.Days = append(doc.Days, day.String())
+	}
+	if s.Location != nil && s.Location != time.UTC {
+		doc.Timezone = s.Location.String()
+	}
+	return doc
+}
+
+func (doc *scheduleDocument) schedule() (Schedule, error) {
+	var s Schedule
+	for _, name := range doc.Days {
+		day, err := parseWeekday(name)
+		if err != nil {
+			return s, err
+		}
+		s.Days = append(s.Days, day)
+	}
+
+	var err error
+	if s.Start, err = parseClock(doc.Start); err != nil {
+		return s, err
+	}
+	if s.End, err = parseClock(doc.End); err != nil {
+		return s, err
+	}
+	if doc.Timezone != "" {
+		if s.Location, err = time.LoadLocation(doc.Timezone); err != nil {
+			return s, fmt.Errorf("schedule: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) || strings.EqualFold(name, day.String()[:3]) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("schedule: unknown day %q", name)
+}
+
+// parseClock parses a "15:04" time of day, "24:00" is the end of the day.
+func parseClock(value string) (time.Duration, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hours, &minutes); err != nil ||
+		hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("schedule: invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}